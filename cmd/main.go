@@ -1,20 +1,37 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	k "github.com/kiquetal/k8s-rules-viewer/internal/kubernetes"
 	"github.com/kiquetal/k8s-rules-viewer/internal/tui"
 	"github.com/rivo/tview"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -23,33 +40,562 @@ func main() {
 	appLabel := flag.String("label", "py-kannel", "Application label to filter resources")
 	namespace := flag.String("namespace", "default", "Kubernetes namespace to search in")
 	krakendConfigMap := flag.String("krakend-map", "krakend-config", "Name of the Krakend ConfigMap to look for")
+	krakendLabel := flag.String("krakend-label", "",
+		"Label selector of the Krakend ConfigMap (e.g. app=gateway), used instead of -krakend-map; the newest one with a parseable config is picked")
+	krakendCompare := flag.String("krakend-compare", "",
+		"Compare the Krakend ConfigMap with this one ([namespace/]name), printing the added, removed and changed endpoints")
+	labelKeys := flag.String("label-keys", "app,app.kubernetes.io/name,",
+		"Ordered, comma-separated label keys tried when matching -label (an empty entry matches the bare label)")
+	output := flag.String("output", "", "Print the rules report in the given format (csv, json, prometheus, markdown) instead of starting the TUI")
+	includeLogs := flag.Int("include-logs", 0, "Append the last N log lines of each app pod to the -output markdown report")
+	outputFile := flag.String("output-file", "", "Write the -output report (or -watch lines) to this file instead of stdout, creating parent directories")
+	appsList := flag.String("apps", "", "Comma-separated app labels to scan, shown as a rules matrix (rows: apps, columns: rules)")
+	appsFile := flag.String("apps-file", "", "File listing app labels to scan, one per line (# starts a comment)")
+	allNamespaces := flag.Bool("all-namespaces", false,
+		"Evaluate the app in every namespace holding its Deployment or Service, one report per namespace (requires -output csv or json)")
+	summary := flag.Bool("summary", false, "Print one dense status line per app (deployment, service, pods, rules, KrakenD) instead of the full panels")
+	watch := flag.Duration("watch", 0, "Re-evaluate the rules at this interval, printing one JSON line per evaluation (requires -output json)")
+	loadTimeout := flag.Duration("load-timeout", 0, "Timeout of the first -watch evaluation, which may be slow while caches warm up (0 for none)")
+	refreshTimeout := flag.Duration("refresh-timeout", 0, "Timeout of each later -watch evaluation; a slower one is skipped (0 for none)")
+	watchEvents := flag.Duration("watch-namespace-events", 0,
+		"Poll the namespace for new Warning events at this interval, popping each up at the top of the TUI for a few seconds")
+	serve := flag.String("serve", "", "Serve /rules (JSON) and /metrics (Prometheus) on this address (e.g. :8080) instead of starting the TUI")
+	serveCache := flag.Duration("serve-cache", 30*time.Second, "How long -serve reuses a rules evaluation before re-evaluating")
+	maxProgressDeadline := flag.Int("max-progress-deadline", 600, "Largest acceptable Deployment progressDeadlineSeconds")
+	maxLimitRatio := flag.Float64("max-limit-ratio", tui.DefaultMaxLimitRequestRatio,
+		"Largest acceptable container limit/request ratio for the Resource Ratio rule")
+	minReplicas := flag.Int("min-replicas", tui.DefaultMinReplicas, "Smallest replica count of the Deployment accepted by the Replica Count rule")
+	minReadyPercent := flag.Int("min-ready-percent", tui.DefaultMinReadyPercent,
+		"Smallest percentage of the Deployment's replicas that must be Ready for the Ready Replicas rule (lower it for canaries)")
+	enableRules := flag.String("enable-rules", "", "Comma-separated names of opt-in rules to evaluate (e.g. \"Startup Probe,Distinct Liveness Probe\")")
+	maxStaticReplicas := flag.Int("max-static-replicas", tui.DefaultMaxStaticReplicas,
+		"Largest replica count of a Deployment without an HPA accepted by the Static Replicas rule")
+	namingPattern := flag.String("naming-pattern", "",
+		"Regular expression the Deployment name, Service name and app label value must match for the Naming Convention rule (default: they must be equal)")
+	gitOpsMarkers := flag.String("gitops-markers", "",
+		"Comma-separated label/annotation keys marking a GitOps-managed Deployment for the GitOps Ownership rule (default: Argo CD and Flux markers)")
+	publicRegistries := flag.String("public-registries", "",
+		"Comma-separated registry hosts or host/namespace prefixes pulled from without credentials for the Image Pull Secrets rule (default: Docker Hub and other well-known public registries)")
+	containerPattern := flag.String("container", "",
+		"Regular expression matching the whole name of the container to stream logs from (default: the first app container)")
+	highlight := flag.String("highlight", "", "Comma-separated regular expressions highlighted in the log views (e.g. a request ID)")
+	logBufferSize := flag.Int("log-buffer-size", tui.DefaultLogReadBufferSize, "Size in bytes of the log read buffer; longer log lines are split")
+	rulesConfigPath := flag.String("rules-config", "", "Path to a YAML rules configuration (custom resource rules)")
+	rulePlugin := flag.String("rule-plugin", "",
+		"External command evaluating custom rules: it reads the app's resources as JSON on stdin and prints a JSON array of rule results")
+	rulePluginTimeout := flag.Duration("rule-plugin-timeout", tui.DefaultRulePluginTimeout, "How long a -rule-plugin run may take before it is killed")
+	requiredAnnotations := flag.String("required-annotations", "",
+		"Comma-separated annotations the Deployment must carry (enables the Deployment Annotations rule)")
+	manifestsDir := flag.String("manifests", "", "Evaluate the rules against the rendered YAML manifests in this directory instead of the live cluster")
+	kubeconfigPaths := flag.String("kubeconfig", "",
+		"Kubeconfig file(s) to merge, separated like KUBECONFIG (default: $KUBECONFIG or ~/.kube/config)")
+	kubeContext := flag.String("context", "", "Kubeconfig context to use (default: the current context)")
+	impersonateUser := flag.String("as", "", "Username to impersonate for the operation (like kubectl --as)")
+	impersonateGroups := flag.String("as-group", "", "Comma-separated groups to impersonate for the operation (like kubectl --as-group)")
+	insecureSkipTLSVerify := flag.Bool("insecure-skip-tls-verify", false,
+		"Don't verify the API server's certificate, making the connection insecure (like kubectl --insecure-skip-tls-verify)")
+	certificateAuthority := flag.String("certificate-authority", "",
+		"Path to a CA certificate file to verify the API server's certificate with, instead of the kubeconfig's (like kubectl --certificate-authority)")
+	symbolMode := flag.String("symbols", tui.SymbolModeAuto, "Status symbols in the rules panel: auto, emoji, ascii or none (PASS/FAIL)")
+	symbolPass := flag.String("symbol-pass", "", "Custom symbol for passing checks, used verbatim instead of -symbols")
+	symbolFail := flag.String("symbol-fail", "", "Custom symbol for failing checks, used verbatim instead of -symbols")
+	redact := flag.Bool("redact", false,
+		"Mask node names, IPv4 addresses and the API server URL in all output with stable placeholders, for sharing reports")
+	noState := flag.Bool("no-state", false,
+		"Don't restore the TUI session (namespace, label, focused panel, toggles) saved on exit, nor save it")
+	quiet := flag.Bool("quiet", false, "Suppress non-essential output on stdout (errors still go to stderr)")
+	panelsFlag := flag.String("panels", strings.Join(dashboardPanels, ","),
+		"Ordered, comma-separated dashboard panels to show ("+strings.Join(dashboardPanels, ", ")+")")
+	rulesVerbosity := flag.String("rules-verbosity", tui.RulesVerbosityAll,
+		"Rules shown in the Rules Compliance panel: summary (pass/fail counts), failures (failing rules with remediation) or all")
+	rulesChecklist := flag.Bool("rules-checklist", false, "Show failing rules as a checklist; Enter reveals remediation and a fix command where one is safe")
+	check := flag.Bool("check", false,
+		"Evaluate the rules once, print the failing ones (or the -output report) and exit with status 1 if any fails, for CI")
+	failOn := flag.String("fail-on", tui.SeverityInfo,
+		"Lowest severity of a failing rule that makes -check exit with status 1: critical, warning or info (any failing rule)")
+	explain := flag.String("explain", "", "Print a detailed explanation of the named rule and exit")
+	describeCmd := flag.String("describe-cmd", "kubectl describe pod {pod} -n {namespace}",
+		"Command run for the selected pod when pressing 'o' ({pod} and {namespace} are substituted)")
 
 	// Parse command-line flags
 	flag.Parse()
 
-	// Display the parameters being used
-	fmt.Printf("Using parameters:\n  Label: %s\n  Namespace: %s\n  Krakend ConfigMap: %s\n",
-		*appLabel, *namespace, *krakendConfigMap)
+	switch *symbolMode {
+	case tui.SymbolModeAuto, tui.SymbolModeEmoji, tui.SymbolModeASCII, tui.SymbolModeNone:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -symbols %q: use auto, emoji, ascii or none\n", *symbolMode)
+		os.Exit(2)
+	}
+
+	switch *rulesVerbosity {
+	case tui.RulesVerbositySummary, tui.RulesVerbosityFailures, tui.RulesVerbosityAll:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -rules-verbosity %q: use summary, failures or all\n", *rulesVerbosity)
+		os.Exit(2)
+	}
+
+	// Custom symbols bypass the -symbols mode and its terminal detection entirely,
+	// and replace the check marks of the deployment and service details too
+	symbols := tui.GetStatusSymbols(*symbolMode)
+	detailSymbols := k.CheckMarkSymbols
+	if *symbolPass != "" || *symbolFail != "" {
+		symbols = tui.CustomStatusSymbols(*symbolPass, *symbolFail)
+		detailSymbols = symbols
+	}
+
+	if *minReplicas < 1 {
+		fmt.Fprintln(os.Stderr, "Invalid -min-replicas: use a positive replica count")
+		os.Exit(2)
+	}
+
+	if *minReadyPercent < 1 || *minReadyPercent > 100 {
+		fmt.Fprintln(os.Stderr, "Invalid -min-ready-percent: use a percentage between 1 and 100")
+		os.Exit(2)
+	}
+
+	if *maxStaticReplicas < 1 {
+		fmt.Fprintln(os.Stderr, "Invalid -max-static-replicas: use a positive replica count")
+		os.Exit(2)
+	}
+
+	if *rulePluginTimeout <= 0 {
+		fmt.Fprintln(os.Stderr, "Invalid -rule-plugin-timeout: use a positive duration")
+		os.Exit(2)
+	}
+
+	if *insecureSkipTLSVerify && *certificateAuthority != "" {
+		fmt.Fprintln(os.Stderr, "-insecure-skip-tls-verify cannot be combined with -certificate-authority")
+		os.Exit(2)
+	}
+	if *manifestsDir != "" && (*insecureSkipTLSVerify || *certificateAuthority != "") {
+		fmt.Fprintln(os.Stderr, "-insecure-skip-tls-verify and -certificate-authority cannot be combined with -manifests")
+		os.Exit(2)
+	}
+	if *certificateAuthority != "" {
+		if _, err := os.Stat(*certificateAuthority); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -certificate-authority: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if *outputFile != "" && *output == "" {
+		fmt.Fprintln(os.Stderr, "-output-file requires -output")
+		os.Exit(2)
+	}
+
+	if *includeLogs < 0 || (*includeLogs > 0 && *output != "markdown") {
+		fmt.Fprintln(os.Stderr, "Invalid -include-logs: use a positive number of lines together with -output markdown")
+		os.Exit(2)
+	}
+
+	if *watch < 0 || (*watch > 0 && *output != "json") {
+		fmt.Fprintln(os.Stderr, "Invalid -watch: use a positive interval together with -output json")
+		os.Exit(2)
+	}
+	if *loadTimeout < 0 || *refreshTimeout < 0 || (*watch == 0 && (*loadTimeout > 0 || *refreshTimeout > 0)) {
+		fmt.Fprintln(os.Stderr, "Invalid -load-timeout or -refresh-timeout: use a positive duration together with -watch")
+		os.Exit(2)
+	}
 
-	// Load Kubernetes config from default location if not specified
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		homeDir, err := os.UserHomeDir()
+	// Collect the apps of a multi-app scan
+	apps := parseList(*appsList)
+	if *appsFile != "" {
+		fileApps, err := readAppsFile(*appsFile)
 		if err != nil {
-			log.Fatalf("Error getting user home dir: %v", err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
 		}
-		kubeconfig = filepath.Join(homeDir, ".kube", "config")
+		apps = append(apps, fileApps...)
+	}
+	if *summary && (*output != "" || *watch > 0 || *serve != "") {
+		fmt.Fprintln(os.Stderr, "-summary cannot be combined with -output, -watch or -serve")
+		os.Exit(2)
+	}
+	if len(apps) > 0 && (*watch > 0 || *serve != "") {
+		fmt.Fprintln(os.Stderr, "-apps and -apps-file cannot be combined with -watch or -serve")
+		os.Exit(2)
 	}
 
-	// Build the Kubernetes config and clientset
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		log.Fatalf("Error building kubeconfig: %s", err)
+	switch *failOn {
+	case tui.SeverityCritical, tui.SeverityWarning, tui.SeverityInfo:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -fail-on %q: use critical, warning or info\n", *failOn)
+		os.Exit(2)
+	}
+	if *failOn != tui.SeverityInfo && !*check {
+		fmt.Fprintln(os.Stderr, "-fail-on requires -check")
+		os.Exit(2)
+	}
+	if *check && (len(apps) > 0 || *allNamespaces || *summary || *watch > 0 || *serve != "") {
+		fmt.Fprintln(os.Stderr, "-check cannot be combined with -apps, -all-namespaces, -summary, -watch or -serve")
+		os.Exit(2)
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	if *allNamespaces && (*output == "" || len(apps) > 0 || *summary || *watch > 0 || *serve != "") {
+		fmt.Fprintln(os.Stderr, "-all-namespaces requires -output and cannot be combined with -apps, -summary, -watch or -serve")
+		os.Exit(2)
+	}
+
+	// Sweep the "namespace label" targets piped on stdin instead of the single -label, unless another
+	// mode says what to evaluate; an empty input falls back to the usual behavior
+	var targets []batchTarget
+	if stdinIsBatchInput() && len(apps) == 0 && !*allNamespaces && !*summary && !*check && *watch == 0 &&
+		*serve == "" && *krakendCompare == "" && *explain == "" {
+		var err error
+		if targets, err = readBatchTargets(os.Stdin, *namespace); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if err := tui.SetLogHighlights(parseList(*highlight)); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -highlight: %v\n", err)
+		os.Exit(2)
+	}
+	if *logBufferSize < 1024 {
+		fmt.Fprintln(os.Stderr, "-log-buffer-size must be at least 1024")
+		os.Exit(2)
+	}
+	tui.SetLogReadBufferSize(*logBufferSize)
+
+	panels, err := parsePanels(*panelsFlag)
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %s", err)
+		fmt.Fprintf(os.Stderr, "Invalid -panels: %v\n", err)
+		os.Exit(2)
+	}
+
+	if _, err := regexp.Compile(*namingPattern); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -naming-pattern: %v\n", err)
+		os.Exit(2)
+	}
+
+	if _, err := regexp.Compile(*containerPattern); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -container pattern: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Explain a rule without connecting to the cluster
+	if *explain != "" {
+		explanation, err := tui.ExplainRule(*explain)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(explanation)
+		return
+	}
+
+	// Reopen the TUI on the app, panel and toggles the last session left, unless the flags say otherwise
+	interactive := *output == "" && !*summary && !*check && *watch == 0 && *serve == "" && len(apps) == 0 &&
+		*krakendCompare == "" && len(targets) == 0
+	var state sessionState
+	if interactive && !*noState {
+		var err error
+		if state, err = loadSessionState(); err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring the saved session: %v\n", err)
+		}
+		explicit := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		if state.Namespace != "" && !explicit["namespace"] {
+			*namespace = state.Namespace
+		}
+		if state.Label != "" && !explicit["label"] {
+			*appLabel = state.Label
+		}
+		if state.LogTimestamps != nil {
+			tui.SetLogTimestampsShown(*state.LogTimestamps)
+		}
+	}
+
+	// Display the parameters being used
+	if !*quiet {
+		fmt.Printf("Using parameters:\n  Label: %s\n  Namespace: %s\n  Krakend ConfigMap: %s\n",
+			*appLabel, *namespace, krakendMapDescription(*krakendConfigMap, *krakendLabel))
+	}
+
+	// Load the custom resource rules first, serving manifests needs the resources they list
+	var unstructuredRules []tui.UnstructuredRuleDefinition
+	if *rulesConfigPath != "" {
+		rulesConfig, err := tui.LoadRulesConfig(*rulesConfigPath)
+		if err != nil {
+			log.Fatalf("Error loading rules config: %v", err)
+		}
+		unstructuredRules = rulesConfig.UnstructuredRules
+	}
+
+	var clientset, refreshClientset kubernetes.Interface
+	var dynamicClient, refreshDynamicClient dynamic.Interface
+	var banner, serverURL string
+	if *manifestsDir != "" {
+		// Evaluate rendered manifests instead of the live cluster, e.g. in CI before anything is applied
+		manifests, err := k.LoadManifests(*manifestsDir)
+		if err != nil {
+			log.Fatalf("Error loading manifests: %v", err)
+		}
+		clientset, dynamicClient = k.NewManifestClients(manifests, *namespace, tui.UnstructuredListKinds(unstructuredRules))
+		refreshClientset, refreshDynamicClient = clientset, dynamicClient
+		banner = fmt.Sprintf("Evaluating manifests in %s, not the live cluster", *manifestsDir)
+	} else {
+		// Build the Kubernetes config and clientset from the merged kubeconfig files
+		config, err := buildKubeConfig(*kubeconfigPaths, *kubeContext)
+		if err != nil {
+			log.Fatalf("Error building kubeconfig: %s", err)
+		}
+
+		// Impersonate a user/service account, e.g. to check what its RBAC lets it see
+		if *impersonateUser != "" || *impersonateGroups != "" {
+			config.Impersonate = rest.ImpersonationConfig{
+				UserName: *impersonateUser,
+				Groups:   parseList(*impersonateGroups),
+			}
+			banner = fmt.Sprintf("Impersonating user %q groups %v - results reflect their permissions",
+				config.Impersonate.UserName, config.Impersonate.Groups)
+			fmt.Fprintf(os.Stderr, "WARNING: %s\n", banner)
+		}
+
+		// Override how the API server's certificate is verified, e.g. for a dev cluster with a self-signed one
+		if *certificateAuthority != "" {
+			config.TLSClientConfig.CAFile, config.TLSClientConfig.CAData = *certificateAuthority, nil
+		}
+		if *insecureSkipTLSVerify {
+			// client-go refuses a root CA together with the insecure flag
+			config.TLSClientConfig.Insecure = true
+			config.TLSClientConfig.CAFile, config.TLSClientConfig.CAData = "", nil
+			insecureWarning := fmt.Sprintf("INSECURE: TLS verification of %s is disabled - do not use against production", config.Host)
+			if banner != "" {
+				banner += " | "
+			}
+			banner += insecureWarning
+			fmt.Fprintf(os.Stderr, "WARNING: %s\n", insecureWarning)
+		}
+
+		serverURL = config.Host
+		// Bound each request by the timeout of the evaluation it belongs to, so a timed out -watch
+		// evaluation left running in the background finishes soon too
+		refreshConfig := rest.CopyConfig(config)
+		config.Timeout, refreshConfig.Timeout = *loadTimeout, *refreshTimeout
+		clientset, dynamicClient = newClients(config)
+		refreshClientset, refreshDynamicClient = newClients(refreshConfig)
+	}
+
+	// Mask cluster-identifying details in everything rendered or exported
+	var redactor *k.Redactor
+	if *redact {
+		redactor = k.NewRedactor(clientset, *namespace, serverURL)
+	}
+
+	// Collect the options for rule evaluation
+	ruleOptions := tui.RuleOptions{
+		Redactor:                   redactor,
+		DynamicClient:              dynamicClient,
+		UnstructuredRules:          unstructuredRules,
+		RequiredAnnotations:        parseList(*requiredAnnotations),
+		EnabledRules:               parseList(*enableRules),
+		MaxProgressDeadlineSeconds: int32(*maxProgressDeadline),
+		GitOpsMarkers:              parseList(*gitOpsMarkers),
+		PublicRegistries:           parseList(*publicRegistries),
+		Manifests:                  *manifestsDir != "",
+		MaxLimitRequestRatio:       *maxLimitRatio,
+		MinReplicas:                *minReplicas,
+		MinReadyPercent:            *minReadyPercent,
+		NamingPattern:              *namingPattern,
+		MaxStaticReplicas:          int32(*maxStaticReplicas),
+		RulePlugin:                 *rulePlugin,
+		RulePluginTimeout:          *rulePluginTimeout,
+	}
+
+	// Print the endpoint-level differences between the KrakenD ConfigMap and another one, e.g. of the next
+	// environment, and exit without starting the TUI
+	if *krakendCompare != "" {
+		otherNamespace, otherMap, found := strings.Cut(*krakendCompare, "/")
+		if !found {
+			otherNamespace, otherMap = *namespace, *krakendCompare
+		}
+		baseMap, err := resolveKrakenDMap(clientset, *namespace, *krakendConfigMap, *krakendLabel)
+		if err != nil {
+			log.Fatalf("Error comparing Krakend ConfigMaps: %v", err)
+		}
+		diff, err := tui.CompareKrakenDConfigMaps(clientset, *namespace, baseMap, otherNamespace, otherMap, *appLabel)
+		if err != nil {
+			log.Fatalf("Error comparing Krakend ConfigMaps: %v", err)
+		}
+		fmt.Print(redactor.Redact(tui.FormatKrakenDConfigDiff(diff, *namespace+"/"+baseMap, otherNamespace+"/"+otherMap, *appLabel)))
+		return
+	}
+
+	// Evaluate each target piped on stdin and print one report keyed by namespace and label per target,
+	// in the -output format (or as text), without starting the TUI
+	if len(targets) > 0 {
+		reports := make([]tui.RulesReport, len(targets))
+		for i, target := range targets {
+			labelSelector, _, _ := resolveLabelSelector(clientset, target.namespace, target.label, parseLabelKeys(*labelKeys))
+			results := tui.EvaluateRules(clientset, target.namespace, labelSelector, ruleOptions)
+			reports[i] = tui.NewRulesReport(results, target.namespace, target.label, time.Now())
+		}
+		if *output == "" {
+			for _, report := range reports {
+				fmt.Printf("== %s/%s ==\n", report.Namespace, report.App)
+				fmt.Println(redactor.Redact(tui.FormatRulesCompliance(report.Results, report.Namespace, symbols, *rulesVerbosity)))
+			}
+			return
+		}
+		report, err := formatAppsReport(*output, reports)
+		if err != nil {
+			log.Fatalf("Error formatting report: %v", err)
+		}
+		if err := writeOutput(*outputFile, report, *quiet); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
+	}
+
+	// Run as a long-lived compliance exporter instead of the TUI
+	if *serve != "" {
+		evaluate := func() []tui.RuleResult {
+			labelSelector, _, _ := resolveLabelSelector(clientset, *namespace, *appLabel, parseLabelKeys(*labelKeys))
+			return tui.EvaluateRules(clientset, *namespace, labelSelector, ruleOptions)
+		}
+		log.Fatal(serveRules(*serve, *serveCache, evaluate, *namespace, *appLabel))
+	}
+
+	// Stream one JSON line per evaluation, headless, until interrupted, re-running
+	// the rules only when the app's resources changed
+	if *watch > 0 {
+		cache := tui.NewRulesCache()
+		refreshOptions := ruleOptions
+		refreshOptions.DynamicClient = refreshDynamicClient
+		evaluate := func(first bool) []tui.RuleResult {
+			client, opts := refreshClientset, refreshOptions
+			if first {
+				client, opts = clientset, ruleOptions
+			}
+			labelSelector, _, _ := resolveLabelSelector(client, *namespace, *appLabel, parseLabelKeys(*labelKeys))
+			return cache.Evaluate(client, *namespace, labelSelector, opts)
+		}
+		out, err := openOutput(*outputFile)
+		if err != nil {
+			log.Fatalf("Error opening output file: %v", err)
+		}
+		if *outputFile != "" && !*quiet {
+			fmt.Fprintf(os.Stderr, "Writing evaluations to %s\n", *outputFile)
+		}
+		if err := watchRules(out, *watch, *loadTimeout, *refreshTimeout, evaluate, cache.Invalidate, *namespace, *appLabel); err != nil {
+			log.Fatalf("Error watching rules: %v", err)
+		}
+		return
+	}
+
+	// Evaluate every app of a multi-app scan, one report per app
+	evaluateApps := func() []tui.RulesReport {
+		reports := make([]tui.RulesReport, len(apps))
+		for i, appName := range apps {
+			labelSelector, _, _ := resolveLabelSelector(clientset, *namespace, appName, parseLabelKeys(*labelKeys))
+			results := tui.EvaluateRules(clientset, *namespace, labelSelector, ruleOptions)
+			reports[i] = tui.NewRulesReport(results, *namespace, appName, time.Now())
+		}
+		return reports
+	}
+
+	// Print one summary line per app and exit without starting the TUI
+	if *summary {
+		summaryApps := apps
+		if len(summaryApps) == 0 {
+			summaryApps = []string{*appLabel}
+		}
+		for _, appName := range summaryApps {
+			labelSelector, _, _ := resolveLabelSelector(clientset, *namespace, appName, parseLabelKeys(*labelKeys))
+			results := tui.EvaluateRules(clientset, *namespace, labelSelector, ruleOptions)
+			fmt.Println(redactor.Redact(summaryLine(clientset, *namespace, appName, labelSelector,
+				*krakendConfigMap, *krakendLabel, results)))
+		}
+		return
+	}
+
+	// Evaluate the app in each namespace it's found in, adding the check that its resources
+	// are found together there, and print one report per namespace
+	if *allNamespaces {
+		namespaces, err := k.GetAppNamespaces(clientset, *appLabel)
+		if err != nil {
+			log.Fatalf("Error finding the app's namespaces: %v", err)
+		}
+		reports := make([]tui.RulesReport, len(namespaces))
+		for i, ns := range namespaces {
+			labelSelector, _, _ := resolveLabelSelector(clientset, ns, *appLabel, parseLabelKeys(*labelKeys))
+			results := tui.EvaluateRules(clientset, ns, labelSelector, ruleOptions)
+			results = append(results, tui.EvaluateNamespaceConsistency(clientset, ns, *appLabel, labelSelector, ruleOptions))
+			reports[i] = tui.NewRulesReport(results, ns, *appLabel, time.Now())
+		}
+		report, err := formatAppsReport(*output, reports)
+		if err != nil {
+			log.Fatalf("Error formatting report: %v", err)
+		}
+		if err := writeOutput(*outputFile, report, *quiet); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
+	}
+
+	// Print the multi-app report and exit without starting the TUI
+	if len(apps) > 0 && *output != "" {
+		report, err := formatAppsReport(*output, evaluateApps())
+		if err != nil {
+			log.Fatalf("Error formatting report: %v", err)
+		}
+		if err := writeOutput(*outputFile, report, *quiet); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
+	}
+
+	// Evaluate the rules once for CI, printing the -output report or else the failing rules, and exit
+	// with status 1 when a rule at or above the -fail-on severity fails
+	if *check {
+		labelSelector, _, _ := resolveLabelSelector(clientset, *namespace, *appLabel, parseLabelKeys(*labelKeys))
+		results := tui.EvaluateRules(clientset, *namespace, labelSelector, ruleOptions)
+		if *output != "" {
+			report, err := formatReport(*output, results, *namespace, *appLabel)
+			if err != nil {
+				log.Fatalf("Error formatting report: %v", err)
+			}
+			if *output == "markdown" {
+				report += markdownAppendix(clientset, *namespace, *appLabel, labelSelector, *containerPattern,
+					ruleOptions.RequiredAnnotations, *includeLogs)
+				report = redactor.Redact(report)
+			}
+			if err := writeOutput(*outputFile, report, *quiet); err != nil {
+				log.Fatalf("Error writing report: %v", err)
+			}
+		} else {
+			fmt.Print(redactor.Redact(tui.FormatRulesCompliance(results, *namespace, symbols, tui.RulesVerbosityFailures)))
+		}
+		if blocking := tui.FailingRulesAtLeast(results, *failOn); len(blocking) > 0 {
+			if !*quiet {
+				fmt.Fprintf(os.Stderr, "%d rule(s) failing at severity %s or above\n", len(blocking), *failOn)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Print the requested report and exit without starting the TUI
+	if *output != "" {
+		labelSelector, _, _ := resolveLabelSelector(clientset, *namespace, *appLabel, parseLabelKeys(*labelKeys))
+		results := tui.EvaluateRules(clientset, *namespace, labelSelector, ruleOptions)
+		report, err := formatReport(*output, results, *namespace, *appLabel)
+		if err != nil {
+			log.Fatalf("Error formatting report: %v", err)
+		}
+		// A Markdown report is a self-contained artifact, e.g. for a postmortem: add the app's
+		// configuration and, with -include-logs, its recent logs
+		if *output == "markdown" {
+			report += markdownAppendix(clientset, *namespace, *appLabel, labelSelector, *containerPattern,
+				ruleOptions.RequiredAnnotations, *includeLogs)
+			report = redactor.Redact(report)
+		}
+		if err := writeOutput(*outputFile, report, *quiet); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
 	}
 
 	// Create a new tview application
@@ -59,185 +605,1890 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Save the session for the next launch when the TUI exits, however it does. The dashboard is
+	// only read on the UI goroutine: its state is snapshotted after each draw, which follows every
+	// focus change and toggle, and the snapshot is what gets saved, also from the signal handler.
+	var currentDash *dashboard
+	var snapshotMu sync.Mutex
+	var stateSnapshot *sessionState
+	app.SetAfterDrawFunc(func(tcell.Screen) {
+		if currentDash == nil {
+			return
+		}
+		snapshot := currentDash.sessionState()
+		snapshotMu.Lock()
+		stateSnapshot = &snapshot
+		snapshotMu.Unlock()
+	})
+	var saveStateOnce sync.Once
+	saveState := func() {
+		saveStateOnce.Do(func() {
+			snapshotMu.Lock()
+			snapshot := stateSnapshot
+			snapshotMu.Unlock()
+			if *noState || snapshot == nil {
+				return
+			}
+			if err := saveSessionState(*snapshot); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving the session: %v\n", err)
+			}
+		})
+	}
+
 	// Handle signals in a separate goroutine
 	go func() {
 		<-sigChan
 		app.Stop()
-		fmt.Println("\nShutting down gracefully...")
+		saveState()
+		if !*quiet {
+			fmt.Println("\nShutting down gracefully...")
+		}
 		os.Exit(0)
 	}()
 
-	// Use a simple loading screen until we fetch data
-	loadingText := tview.NewTextView().
-		SetTextAlign(tview.AlignCenter).
-		SetText("Loading data from Kubernetes cluster...\nThis may take a few seconds.")
-	loadingText.SetBorder(true).SetTitle("Loading")
-	app.SetRoot(loadingText, true)
+	// A multi-app scan shows the rules matrix instead of the single-app dashboard
+	if len(apps) > 0 {
+		// Use a simple loading screen until we fetch data
+		loadingText := tview.NewTextView().
+			SetTextAlign(tview.AlignCenter).
+			SetText("Loading data from Kubernetes cluster...\nThis may take a few seconds.")
+		loadingText.SetBorder(true).SetTitle("Loading")
+		app.SetRoot(loadingText, true)
 
-	// Pre-fetch the Kubernetes data in a goroutine to avoid blocking the UI
-	go func() {
-		// Fix the label selector format - it should match what's actually used in Kubernetes
-		labelSelector := fmt.Sprintf("app=%s", *appLabel)
-		altLabelSelector := fmt.Sprintf("app.kubernetes.io/name=%s", *appLabel)
+		go func() {
+			reports := evaluateApps()
+			app.QueueUpdateDraw(func() {
+				renderAppsMatrix(app, *namespace, reports, symbols, *rulesVerbosity)
+			})
+		}()
+	} else {
+		// loadDashboard shows the dashboard of an app; it runs again when another namespace and label
+		// are picked after nothing matched
+		var loadDashboard func(namespace, appLabel string, candidateKeys []string)
+		var stopEventWatch context.CancelFunc
+		loadDashboard = func(namespace, appLabel string, candidateKeys []string) {
+			// Evaluate the rules of the app's pods, on each refresh and on their own with 'R'
+			evaluateRules := func(labelSelector string) []tui.RuleResult {
+				return tui.EvaluateRules(clientset, namespace, labelSelector, ruleOptions)
+			}
+			formatRules := func(results []tui.RuleResult) string {
+				return tview.Escape(tui.FormatRulesCompliance(results, namespace, symbols, *rulesVerbosity))
+			}
 
-		// Try first with our primary selector
-		podNames := k.GetPodNamesByLabel(clientset, *namespace, labelSelector)
-		podInfoList := k.GetPodInfoByLabel(clientset, *namespace, labelSelector)
+			// Show the dashboard right away and fill each panel in as soon as its data arrives, so a slow
+			// or failing fetch (e.g. no read access to the KrakenD ConfigMap) never holds up the others
+			dash := renderTUI(app, appLabel, namespace, krakendMapDescription(*krakendConfigMap, *krakendLabel), *describeCmd, *containerPattern,
+				clientset, banner, *rulesChecklist, panels, evaluateRules, formatRules)
+			// The first dashboard picks up the toggles and focus of the saved session
+			if currentDash == nil {
+				dash.rawDetails = state.RawDetails
+				dash.focusPanel(state.FocusedPanel)
+			}
+			dash.redactor = redactor
+			currentDash = dash
 
-		// If no pods found, try with the alternative selector
-		if len(podNames) == 0 {
-			podNames = k.GetPodNamesByLabel(clientset, *namespace, altLabelSelector)
-			podInfoList = k.GetPodInfoByLabel(clientset, *namespace, altLabelSelector)
-			if len(podNames) > 0 {
-				labelSelector = altLabelSelector // Update if we found pods with this selector
+			// Pop up the warning events happening from now on, e.g. a FailedScheduling during a rollout
+			if stopEventWatch != nil {
+				stopEventWatch()
+				stopEventWatch = nil
+			}
+			if *watchEvents > 0 {
+				var watchCtx context.Context
+				watchCtx, stopEventWatch = context.WithCancel(context.Background())
+				go watchNamespaceEvents(watchCtx, dash, clientset, namespace, *watchEvents, redactor)
 			}
-		}
 
-		// If still no pods found, try just matching by the app name without explicit label key
-		if len(podNames) == 0 {
-			// Try a more permissive selector
-			podNames = k.GetPodNamesByLabel(clientset, *namespace, *appLabel)
-			podInfoList = k.GetPodInfoByLabel(clientset, *namespace, *appLabel)
-			if len(podNames) > 0 {
-				labelSelector = *appLabel // Update if we found pods with this selector
+			// Fetch dynamic Deployment, Service info, validated or raw as toggled with 'v'
+			dash.loadDetails = func() {
+				symbols := detailSymbols
+				if dash.rawDetails {
+					symbols = k.RawSymbols
+				}
+				go func() {
+					deploymentInfo := k.GetDeploymentInfo(clientset, namespace, appLabel, ruleOptions.RequiredAnnotations, symbols)
+					app.QueueUpdateDraw(func() { dash.SetDeployment(redactor.Redact(deploymentInfo)) })
+				}()
+				go func() {
+					serviceInfo := k.GetServiceInfo(clientset, namespace, appLabel, symbols)
+					app.QueueUpdateDraw(func() { dash.SetService(redactor.Redact(serviceInfo)) })
+				}()
 			}
-		}
 
-		// Fetch dynamic Deployment, Service info
-		deploymentInfo := k.GetDeploymentInfo(clientset, *namespace, *appLabel)
-		serviceInfo := k.GetServiceInfo(clientset, *namespace, *appLabel)
+			// Fetch every panel, again each time 'r' is pressed
+			dash.refresh = func(first bool) {
+				rulesGeneration := dash.startRules()
 
-		// Format the pod information into a single string for display
-		var podInfoBuilder strings.Builder
-		podInfoBuilder.WriteString(fmt.Sprintf("Pods with label '%s':\n\n", labelSelector))
+				// Fetch the cluster summary shown in the header
+				go func() {
+					clusterInfo := k.GetClusterInfo(clientset)
+					app.QueueUpdateDraw(func() { dash.SetClusterInfo(redactor.Redact(clusterInfo)) })
+				}()
 
-		for i, podInfo := range podInfoList {
-			podInfoBuilder.WriteString(fmt.Sprintf("--- Pod %d ---\n%s\n", i+1, podInfo))
-		}
+				// Fetch the namespace-wide warnings, which often explain issues per-pod data misses;
+				// once watched, the watch keeps them up to date
+				if first || *watchEvents == 0 {
+					go func() {
+						namespaceWarnings := formatNamespaceWarnings(clientset, namespace)
+						app.QueueUpdateDraw(func() { dash.SetNamespaceWarnings(redactor.Redact(namespaceWarnings)) })
+					}()
+				}
 
-		podInfo := podInfoBuilder.String()
+				dash.loadDetails()
 
-		// Get rules compliance information
-		rulesCompliance := tui.GetRulesCompliance(clientset, *namespace, labelSelector)
+				// Get Krakend config check information
+				go func() {
+					krakendMap, err := resolveKrakenDMap(clientset, namespace, *krakendConfigMap, *krakendLabel)
+					krakendConfigCheck := ""
+					if err == nil {
+						krakendConfigCheck, err = tui.KrakenDBackendServiceCheck(clientset, namespace, krakendMap, appLabel)
+					}
+					if err != nil {
+						krakendConfigCheck = fmt.Sprintf("Error analyzing Krakend ConfigMap: %v", err)
+					} else if *krakendLabel != "" {
+						krakendConfigCheck = fmt.Sprintf("ConfigMap: %s (selected by %s)\n\n%s", krakendMap, *krakendLabel, krakendConfigCheck)
+					}
+					app.QueueUpdateDraw(func() { dash.SetKrakend(redactor.Redact(krakendConfigCheck)) })
+				}()
 
-		// Get Krakend config check information
-		krakendConfigCheck, err := tui.KrakenDBackendServiceCheck(clientset, *namespace, *krakendConfigMap, *appLabel)
-		if err != nil {
-			krakendConfigCheck = fmt.Sprintf("Error analyzing Krakend ConfigMap: %v", err)
-		}
+				// The pod, batch, PVC and rules panels depend on the label selector
+				go func() {
+					// Try each candidate label key in order until one matches some pods
+					labelSelector, matchedKey, podNames := resolveLabelSelector(clientset, namespace, appLabel, candidateKeys)
 
-		// Update the UI with the fetched data
-		app.QueueUpdateDraw(func() {
-			renderTUI(app, *appLabel, *namespace, *krakendConfigMap, labelSelector,
-				deploymentInfo, serviceInfo, podInfo, rulesCompliance, krakendConfigCheck)
-		})
-	}()
+					go func() {
+						podInfo := formatPodInfo(clientset, namespace, appLabel, labelSelector, matchedKey, podNames, candidateKeys)
+						app.QueueUpdateDraw(func() {
+							dash.SetPods(labelSelector, podNames, redactor.Redact(podInfo))
+							// Offer to pick another namespace and label rather than leaving the panels empty
+							if first && len(podNames) == 0 {
+								dash.promptForApp(strings.Join(candidateKeys, ","), loadDashboard)
+							}
+						})
+					}()
+
+					// Fetch the app's Jobs and CronJobs, shown only when there are some
+					go func() {
+						batchInfo := k.GetBatchInfo(clientset, namespace, labelSelector)
+						app.QueueUpdateDraw(func() { dash.SetBatch(redactor.Redact(batchInfo)) })
+					}()
+
+					// Fetch the PersistentVolumeClaims used by the pods, shown only for stateful apps
+					go func() {
+						pvcInfo := k.GetPVCInfo(clientset, namespace, labelSelector)
+						app.QueueUpdateDraw(func() { dash.SetPVC(redactor.Redact(pvcInfo)) })
+					}()
+
+					// Get rules compliance information, marking the rules that flipped since the previous evaluation
+					ruleResults := evaluateRules(labelSelector)
+					app.QueueUpdateDraw(func() { dash.showRules(rulesGeneration, ruleResults) })
+				}()
+			}
+			dash.refresh(true)
+		}
+		loadDashboard(*namespace, *appLabel, parseLabelKeys(*labelKeys))
+	}
 
 	// Run the application and handle any errors
 	if err := app.Run(); err != nil {
 		log.Fatalf("Error running the application: %v", err)
 	}
+	saveState()
 
-	fmt.Println("Application terminated normally")
+	if !*quiet {
+		fmt.Println("Application terminated normally")
+	}
 }
 
-// renderTUI will render the dashboard with pre-fetched data
-func renderTUI(app *tview.Application, appLabel, namespace, krakendMap,
-	labelSelector, deploymentInfo, serviceInfo, podInfo, rulesCompliance, krakendConfigCheck string) {
+// sessionState is what the TUI restores on the next launch (unless -no-state): the app last shown,
+// the focused panel and the toggles
+type sessionState struct {
+	Namespace    string `json:"namespace,omitempty"`
+	Label        string `json:"label,omitempty"`
+	FocusedPanel string `json:"focused_panel,omitempty"`
+	// RawDetails is the raw rendering of the Deployment and Service panels ('v')
+	RawDetails bool `json:"raw_details,omitempty"`
+	// LogTimestamps is whether log views show timestamps ('t'), nil for the default
+	LogTimestamps *bool `json:"log_timestamps,omitempty"`
+}
 
-	// Create the main layout (using Flex to organize the UI)
-	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+// sessionStatePath returns where the session state is saved, in the user config directory
+// (e.g. ~/.config/k8s-rules-viewer/state.json)
+func sessionStatePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "k8s-rules-viewer", "state.json"), nil
+}
 
-	// Add the header (title) with dynamic parameters
-	header := tview.NewTextView().
-		SetTextAlign(tview.AlignCenter).
-		SetText(fmt.Sprintf("k8s-viewer-rules - Label: %s - Namespace: %s", appLabel, namespace))
-	mainFlex.AddItem(header, 3, 0, false)
-
-	// Create content layout (deployment, service, pod info displayed side by side)
-	contentFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
-
-	// Deployment Info Section
-	deploymentTextView := tview.NewTextView()
-	deploymentTextView.SetBorder(true)
-	deploymentTextView.SetTitle("Deployment Details")
-	deploymentTextView.SetText(deploymentInfo)
-	deploymentTextView.SetScrollable(true)
-	contentFlex.AddItem(deploymentTextView, 0, 1, true)
-
-	// Service Info Section
-	serviceTextView := tview.NewTextView()
-	serviceTextView.SetBorder(true)
-	serviceTextView.SetTitle("Service Details")
-	serviceTextView.SetText(serviceInfo)
-	serviceTextView.SetScrollable(true)
-	contentFlex.AddItem(serviceTextView, 0, 1, true)
-
-	// Pod Info Section - now using the combined information from all pods with scrolling
-	podTextView := tview.NewTextView()
-	podTextView.SetBorder(true)
-	podTextView.SetTitle(fmt.Sprintf("Pod Monitoring (label: %s)", labelSelector))
-	podTextView.SetText(podInfo)
-	podTextView.SetScrollable(true) // Enable scrolling
-	podTextView.SetDynamicColors(true)
-	contentFlex.AddItem(podTextView, 0, 1, true)
-
-	// Add content section to the main layout
-	mainFlex.AddItem(contentFlex, 0, 1, true)
-
-	// Rules Compliance Section
-	rulesTextView := tview.NewTextView()
-	rulesTextView.SetBorder(true)
-	rulesTextView.SetTitle("Rules Compliance")
-	rulesTextView.SetText(rulesCompliance)
-	rulesTextView.SetScrollable(true)
-	mainFlex.AddItem(rulesTextView, 0, 1, true)
-
-	// Krakend Config Check Section
-	krakendTextView := tview.NewTextView()
-	krakendTextView.SetBorder(true)
-	krakendTextView.SetTitle(fmt.Sprintf("Krakend Config Check (%s)", krakendMap))
-	krakendTextView.SetText(krakendConfigCheck)
-	krakendTextView.SetScrollable(true)
-	mainFlex.AddItem(krakendTextView, 0, 1, true)
+// loadSessionState reads the saved session state, empty when there is none yet
+func loadSessionState() (sessionState, error) {
+	var state sessionState
+	path, err := sessionStatePath()
+	if err != nil {
+		return state, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return sessionState{}, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	return state, nil
+}
 
-	// Add help text at the bottom
-	helpText := tview.NewTextView().
-		SetTextAlign(tview.AlignCenter).
-		SetText("Use Tab to switch focus between panels. Use arrow keys to scroll content. Press Ctrl+C to exit.")
-	mainFlex.AddItem(helpText, 1, 0, false)
+// saveSessionState writes the session state for the next launch
+func saveSessionState(state sessionState) error {
+	path, err := sessionStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// newClients creates the typed and dynamic clients for the config, forgetting what was discovered
+// about the cluster through earlier clients
+func newClients(config *rest.Config) (kubernetes.Interface, dynamic.Interface) {
+	k.InvalidateDiscoveryCache()
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("Error creating Kubernetes client: %s", err)
+	}
 
-	// Store all focusable views in order
-	focusableViews := []tview.Primitive{
-		deploymentTextView,
-		serviceTextView,
-		podTextView,
-		rulesTextView,
-		krakendTextView,
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("Error creating Kubernetes dynamic client: %s", err)
 	}
+	return clientset, dynamicClient
+}
 
-	// Set the initial focus to the first view
-	app.SetFocus(deploymentTextView)
+// buildKubeConfig merges the given kubeconfig files (a KUBECONFIG-style path list) in order, like
+// kubectl does, and selects the context. Without files, KUBECONFIG or ~/.kube/config is used.
+func buildKubeConfig(paths, context string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if paths != "" {
+		var precedence []string
+		for _, path := range filepath.SplitList(paths) {
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("error reading kubeconfig %s: %v", path, err)
+			}
+			precedence = append(precedence, path)
+		}
+		loadingRules.Precedence = precedence
+	}
 
-	// Track current focus index
-	currentFocus := 0
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
 
-	// Set the root layout and render the TUI
-	app.SetRoot(mainFlex, true)
-
-	// Set input capture to handle tab navigation between panels
-	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
-			// Move to next focusable view
-			currentFocus = (currentFocus + 1) % len(focusableViews)
-			app.SetFocus(focusableViews[currentFocus])
-			return nil
-		} else if event.Key() == tcell.KeyBacktab {
-			// Move to previous focusable view
-			currentFocus = (currentFocus - 1 + len(focusableViews)) % len(focusableViews)
-			app.SetFocus(focusableViews[currentFocus])
-			return nil
+// parseLabelKeys splits the -label-keys flag into its ordered candidate keys
+func parseLabelKeys(value string) []string {
+	keys := strings.Split(value, ",")
+	for i, key := range keys {
+		keys[i] = strings.TrimSpace(key)
+	}
+	return keys
+}
+
+// parseList splits a comma-separated flag value, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		return event
+	}
+	return items
+}
+
+// dashboardPanels lists the panels -panels can select, in their default order. The detail panels
+// share the top row of the dashboard, the other panels take a full-width row each.
+var dashboardPanels = []string{"deployment", "service", "pods", "jobs", "pvc", "rules", "krakend", "warnings"}
+
+// detailPanels are the panels laid out side by side in the top row
+var detailPanels = map[string]bool{"deployment": true, "service": true, "pods": true, "jobs": true, "pvc": true}
+
+// parsePanels validates the -panels list, keeping its order
+func parsePanels(value string) ([]string, error) {
+	panels := parseList(strings.ToLower(value))
+	if len(panels) == 0 {
+		return nil, fmt.Errorf("no panels listed")
+	}
+	seen := make(map[string]bool)
+	for _, panel := range panels {
+		if !slices.Contains(dashboardPanels, panel) {
+			return nil, fmt.Errorf("unknown panel %q (available: %s)", panel, strings.Join(dashboardPanels, ", "))
+		}
+		if seen[panel] {
+			return nil, fmt.Errorf("panel %q is listed twice", panel)
+		}
+		seen[panel] = true
+	}
+	return panels, nil
+}
+
+// labelSelectorFor builds the selector for a label key, an empty key selects on the bare label
+func labelSelectorFor(key, appLabel string) string {
+	if key == "" {
+		return appLabel
+	}
+	return fmt.Sprintf("%s=%s", key, appLabel)
+}
+
+// resolveLabelSelector tries each candidate label key in order until one matches some pods.
+// It returns the selector to use, the matched key (empty if none matched) and the matching pod names.
+func resolveLabelSelector(clientset kubernetes.Interface, namespace, appLabel string, candidateKeys []string) (string, string, []string) {
+	for _, key := range candidateKeys {
+		selector := labelSelectorFor(key, appLabel)
+		if names := k.GetPodNamesByLabel(clientset, namespace, selector); len(names) > 0 {
+			return selector, describeLabelKey(key), names
+		}
+	}
+	return labelSelectorFor(candidateKeys[0], appLabel), "", nil
+}
+
+// checklistHint is shown in the rules checklist detail area while no fix is revealed
+const checklistHint = "Press Enter on a failing rule to show how to fix it"
+
+// newRulesChecklist builds the rules checklist: a list of the failing rules and a detail area where
+// Enter reveals the remediation and, for rules with a safe deterministic fix, the kubectl command.
+// It returns the whole section and the list that takes focus.
+func newRulesChecklist(results []tui.RuleResult) (tview.Primitive, tview.Primitive) {
+	var failing []tui.RuleResult
+	for _, result := range results {
+		if !result.Passed {
+			failing = append(failing, result)
+		}
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true)
+	list.SetTitle(fmt.Sprintf("Rules Checklist (%d failing of %d)", len(failing), len(results)))
+
+	detail := tview.NewTextView()
+	detail.SetBorder(true)
+	detail.SetTitle("Fix")
+	detail.SetDynamicColors(true)
+	detail.SetWordWrap(true)
+	detail.SetText(checklistHint)
+
+	if len(failing) == 0 {
+		list.AddItem("All rules pass", "", 0, nil)
+		detail.SetText("")
+	}
+	for _, result := range failing {
+		list.AddItem(fmt.Sprintf("[%s] %s", strings.ToUpper(result.Severity), result.Name), result.Description, 0, nil)
+	}
+
+	// Enter toggles the fix details of the selected rule
+	shown := -1
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		if len(failing) == 0 {
+			return
+		}
+		if shown == index {
+			shown = -1
+			detail.SetText(checklistHint)
+			return
+		}
+		shown = index
+		result := failing[index]
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("[yellow]%s[white]\n%s\n\n", tview.Escape(result.Name), tview.Escape(result.Description)))
+		if result.Remediation != "" {
+			sb.WriteString(fmt.Sprintf("Remediation: %s\n\n", tview.Escape(result.Remediation)))
+		}
+		if result.FixCommand != "" {
+			sb.WriteString(fmt.Sprintf("Fix command:\n[green]%s[white]\n", tview.Escape(result.FixCommand)))
+		} else {
+			sb.WriteString("No safe automatic fix for this rule; apply the remediation by hand.\n")
+		}
+		detail.SetText(sb.String())
+	})
+	list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		if shown != -1 && shown != index {
+			shown = -1
+			detail.SetText(checklistHint)
+		}
+	})
+
+	section := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(list, 0, 1, true).
+		AddItem(detail, 0, 1, false)
+	return section, list
+}
+
+// formatNamespaceWarnings lists the most recent non-Normal events in the namespace,
+// colored by event type
+func formatNamespaceWarnings(clientset kubernetes.Interface, namespace string) string {
+	events, err := k.GetNamespaceWarnings(clientset, namespace, namespaceWarningsLimit)
+	if err != nil {
+		return fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+	}
+	return formatWarningEvents(events)
+}
+
+// formatWarningEvents renders warning events one per line, in the given order
+func formatWarningEvents(events []corev1.Event) string {
+	if len(events) == 0 {
+		return "[green]No warning events in the namespace[white]"
+	}
+
+	var sb strings.Builder
+	for _, event := range events {
+		sb.WriteString(formatWarningEvent(&event) + "\n")
+	}
+	return sb.String()
+}
+
+// formatWarningEvent renders one warning event, in red when it isn't even a Warning
+func formatWarningEvent(event *corev1.Event) string {
+	color := "yellow"
+	if event.Type != corev1.EventTypeWarning {
+		color = "red"
+	}
+	return fmt.Sprintf("[%s]%s %s %s/%s: %s[white] (x%d)", color,
+		k.EventTime(event).Format(time.RFC3339), event.Reason,
+		event.InvolvedObject.Kind, event.InvolvedObject.Name, tview.Escape(event.Message), max(event.Count, 1))
+}
+
+// eventOccurrence identifies one occurrence of an event: a repeated event keeps its name and bumps its count
+func eventOccurrence(event *corev1.Event) string {
+	return fmt.Sprintf("%s/%d", event.Name, event.Count)
+}
+
+// watchNamespaceEvents polls the namespace's warning events every interval until ctx is done. Events that
+// weren't there at the first poll are shown as a toast on the dashboard, and kept in the Namespace Warnings
+// panel on top of the warnings it started with, up to namespaceWarningsScrollback of them.
+func watchNamespaceEvents(ctx context.Context, dash *dashboard, clientset kubernetes.Interface, namespace string,
+	interval time.Duration, redactor *k.Redactor) {
+	seen := make(map[string]bool)
+	var scrollback []corev1.Event
+	first := true
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, err := k.GetNamespaceWarnings(clientset, namespace, 0)
+		if err == nil {
+			var fresh []corev1.Event
+			for _, event := range events {
+				if key := eventOccurrence(&event); !seen[key] {
+					seen[key] = true
+					fresh = append(fresh, event)
+				}
+			}
+			if first {
+				scrollback = fresh[:min(len(fresh), namespaceWarningsLimit)]
+				first = false
+			} else if len(fresh) > 0 {
+				scrollback = append(fresh, scrollback...)
+				scrollback = scrollback[:min(len(scrollback), namespaceWarningsScrollback)]
+
+				toastLines := make([]string, 0, maxToastLines)
+				for i := range fresh[:min(len(fresh), maxToastLines)] {
+					toastLines = append(toastLines, formatWarningEvent(&fresh[i]))
+				}
+				if len(fresh) > maxToastLines {
+					toastLines[maxToastLines-1] += fmt.Sprintf(" (+%d more in Namespace Warnings)", len(fresh)-maxToastLines)
+				}
+				toast := redactor.Redact(strings.Join(toastLines, "\n"))
+				warnings := redactor.Redact(formatWarningEvents(scrollback))
+				dash.app.QueueUpdateDraw(func() {
+					dash.SetWarningsScrollback(warnings)
+					dash.ShowToast(toast)
+				})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatPodInfo describes the pods matched by the label selector, each wrapped in a region so it
+// can be highlighted when selected, and explains why nothing matched when there are none
+func formatPodInfo(clientset kubernetes.Interface, namespace, appLabel, labelSelector, matchedKey string,
+	podNames, candidateKeys []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Pods with label '%s':\n", labelSelector))
+	if matchedKey != "" {
+		sb.WriteString(fmt.Sprintf("Matched label key: %s\n\n", matchedKey))
+	} else {
+		keyNames := make([]string, len(candidateKeys))
+		for i, key := range candidateKeys {
+			keyNames[i] = describeLabelKey(key)
+		}
+		sb.WriteString(fmt.Sprintf("No label key matched (tried: %s)\n\n", strings.Join(keyNames, ", ")))
+	}
+
+	// Explain why nothing matched instead of only reporting that no pods were found
+	if len(podNames) == 0 {
+		sb.WriteString(diagnoseNoPods(clientset, namespace, appLabel, candidateKeys))
+	}
+
+	// The app's total footprint, for right-sizing and quota requests
+	if pods, err := k.GetPodsByLabel(clientset, namespace, labelSelector); err == nil && len(pods) > 0 {
+		usage, usageErr := k.GetPodsUsage(clientset, namespace, pods)
+		sb.WriteString(k.FormatPodResourceTotals(k.SumPodResources(pods), usage))
+		if usageErr != nil {
+			sb.WriteString(fmt.Sprintf("  Usage unavailable: %s\n", tview.Escape(usageErr.Error())))
+		}
+		sb.WriteString("\n")
+	}
+
+	for i, podInfo := range k.GetPodInfoByLabel(clientset, namespace, labelSelector) {
+		sb.WriteString(fmt.Sprintf("[\"pod-%d\"]--- Pod %d ---[\"\"]\n%s\n", i, i+1, podInfo))
+	}
+	return sb.String()
+}
+
+// diagnoseNoPods explains why no pods matched: the selectors tried, the state of the
+// app's deployment and recent scheduling/creation failures in the namespace
+func diagnoseNoPods(clientset kubernetes.Interface, namespace, appLabel string, candidateKeys []string) string {
+	var sb strings.Builder
+	sb.WriteString("[yellow]Why no pods?[white]\n")
+
+	sb.WriteString("Selectors tried:\n")
+	for _, key := range candidateKeys {
+		sb.WriteString(fmt.Sprintf("  - %s\n", tview.Escape(labelSelectorFor(key, appLabel))))
+	}
+
+	deployment, err := k.GetDeployment(clientset, namespace, appLabel)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Deployment '%s': not found (%s)\n", tview.Escape(appLabel), tview.Escape(err.Error())))
+	} else {
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		sb.WriteString(fmt.Sprintf("Deployment '%s': %d desired, %d ready\n",
+			deployment.Name, desired, deployment.Status.ReadyReplicas))
+		if desired == 0 {
+			sb.WriteString("  Deployment is scaled to 0 replicas\n")
+		}
+		// Label keys and values may contain brackets that tview would take for tags
+		sb.WriteString(fmt.Sprintf("  Pod template labels: %s\n", tview.Escape(fmt.Sprint(deployment.Spec.Template.Labels))))
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				sb.WriteString(fmt.Sprintf("  [red]%s: %s[white]\n", condition.Type, tview.Escape(condition.Message)))
+			}
+		}
+	}
+
+	for _, reason := range []string{"FailedScheduling", "FailedCreate"} {
+		events, err := k.GetRecentEvents(clientset, namespace, "reason="+reason, 5)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("%s events: %s\n", reason, tview.Escape(err.Error())))
+			continue
+		}
+		if len(events) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("Recent %s events:\n", reason))
+		for _, event := range events {
+			sb.WriteString(fmt.Sprintf("  [red]%s %s/%s: %s[white]\n",
+				k.EventTime(&event).Format(time.RFC3339), event.InvolvedObject.Kind, event.InvolvedObject.Name, tview.Escape(event.Message)))
+		}
+	}
+
+	return sb.String() + "\n"
+}
+
+// serveRules runs an HTTP server exposing the rules as JSON on /rules and as Prometheus
+// metrics on /metrics. Evaluations are cached for cacheTTL so scrapes don't hammer the API server.
+func serveRules(addr string, cacheTTL time.Duration, evaluate func() []tui.RuleResult, namespace, appLabel string) error {
+	var mu sync.Mutex
+	var cached []tui.RuleResult
+	var evaluatedAt time.Time
+
+	results := func() ([]tui.RuleResult, time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cached == nil || time.Since(evaluatedAt) >= cacheTTL {
+			cached = evaluate()
+			evaluatedAt = time.Now()
+		}
+		return cached, evaluatedAt
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rules", func(w http.ResponseWriter, r *http.Request) {
+		rules, at := results()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tui.NewRulesReport(rules, namespace, appLabel, at)); err != nil {
+			log.Printf("Error writing /rules response: %v", err)
+		}
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		rules, _ := results()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, tui.FormatRulesPrometheus(rules, namespace, appLabel))
+	})
+
+	log.Printf("Serving rules for %s in namespace %s on %s (/rules, /metrics)", appLabel, namespace, addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// openOutput selects where reports are written: the -output-file path, created along with its
+// parent directories, or stdout when the path is empty
+func openOutput(path string) (*os.File, error) {
+	if path == "" {
+		return os.Stdout, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// writeOutput writes a formatted report to the -output-file path, or stdout when empty,
+// reporting the path written on stderr
+func writeOutput(path, report string, quiet bool) error {
+	out, err := openOutput(path)
+	if err != nil {
+		return err
+	}
+	if _, err := out.WriteString(report); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Wrote report to %s\n", path)
+	}
+	return nil
+}
+
+// formatReport renders rule results in the requested output format
+func formatReport(format string, results []tui.RuleResult, namespace, appLabel string) (string, error) {
+	switch format {
+	case "csv":
+		return tui.FormatRulesCSV(results, namespace, appLabel)
+	case "json":
+		return tui.FormatRulesJSON(results, namespace, appLabel)
+	case "prometheus":
+		return tui.FormatRulesPrometheus(results, namespace, appLabel), nil
+	case "markdown":
+		return tui.FormatRulesMarkdown(results, namespace, appLabel), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// maxReportLogBytes caps the logs -include-logs appends to a Markdown report
+const maxReportLogBytes = 256 * 1024
+
+// markdownAppendix renders the sections a Markdown report adds after the rules: the Deployment and Service as
+// they are and, with includeLogs > 0, the last includeLogs log lines of each app pod without color tags. The
+// logs are capped at maxReportLogBytes in total, keeping the most recent lines, and a truncation is noted.
+func markdownAppendix(clientset kubernetes.Interface, namespace, appLabel, labelSelector, containerPattern string,
+	requiredAnnotations []string, includeLogs int) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Deployment\n\n```text\n")
+	sb.WriteString(strings.TrimRight(tui.StripColorTags(
+		k.GetDeploymentInfo(clientset, namespace, appLabel, requiredAnnotations, k.RawSymbols)), "\n"))
+	sb.WriteString("\n```\n\n## Service\n\n```text\n")
+	sb.WriteString(strings.TrimRight(tui.StripColorTags(k.GetServiceInfo(clientset, namespace, appLabel, k.RawSymbols)), "\n"))
+	sb.WriteString("\n```\n")
+	if includeLogs == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Logs (last %d lines per pod)\n", includeLogs))
+	pods, err := k.GetPodsByLabel(clientset, namespace, labelSelector)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\n%v\n", err))
+		return sb.String()
+	}
+	if len(pods) == 0 {
+		sb.WriteString("\nNo pods found.\n")
+		return sb.String()
+	}
+
+	budget := maxReportLogBytes
+	for i, pod := range pods {
+		if budget <= 0 {
+			sb.WriteString(fmt.Sprintf("\n_Logs truncated: the %d KiB limit was reached, %d more pod(s) omitted._\n",
+				maxReportLogBytes/1024, len(pods)-i))
+			break
+		}
+		container, err := tui.ResolveContainer(clientset, namespace, pod.Name, containerPattern)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n%v\n", pod.Name, err))
+			continue
+		}
+		logs, err := tui.FetchPodLogs(clientset, namespace, pod.Name, container, int64(includeLogs))
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("\n### %s/%s\n\n%v\n", pod.Name, container, err))
+			continue
+		}
+		logs = tui.StripColorTags(logs)
+		truncated := len(logs) > budget
+		if truncated {
+			// Keep the most recent lines that fit, starting on a line boundary
+			logs = logs[len(logs)-budget:]
+			if newline := strings.IndexByte(logs, '\n'); newline >= 0 {
+				logs = logs[newline+1:]
+			}
+		}
+		budget -= len(logs)
+		sb.WriteString(fmt.Sprintf("\n### %s/%s\n\n```text\n%s\n```\n", pod.Name, container, strings.TrimRight(logs, "\n")))
+		if truncated {
+			sb.WriteString(fmt.Sprintf("\n_Logs truncated to the most recent lines: the %d KiB limit was reached._\n",
+				maxReportLogBytes/1024))
+		}
+	}
+	return sb.String()
+}
+
+// watchFullEvaluationEvery is how many -watch evaluations may reuse cached rule results before
+// invalidating them, picking up changes to inputs the cache doesn't track (nodes, HPAs, ConfigMaps)
+const watchFullEvaluationEvery = 10
+
+// watchRules evaluates the rules every interval and writes each evaluation to out as one
+// JSON line, until SIGINT or SIGTERM. Files (os.Stdout included) are unbuffered, so every line
+// reaches a consumer tailing the output as soon as it is written. invalidate is called every
+// watchFullEvaluationEvery evaluations to force a full one.
+//
+// The first evaluation fails the watch when it takes longer than loadTimeout. A later one taking
+// longer than refreshTimeout is skipped, and no new evaluation starts until it has finished, so
+// slow refreshes on a congested cluster don't pile up. A zero timeout waits indefinitely.
+func watchRules(out *os.File, interval, loadTimeout, refreshTimeout time.Duration, evaluate func(first bool) []tui.RuleResult,
+	invalidate func(), namespace, appLabel string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// timedOut receives the results of a skipped evaluation still running in the background
+	var timedOut chan []tui.RuleResult
+	for evaluations := 1; ; evaluations++ {
+		if timedOut != nil {
+			select {
+			case <-timedOut:
+				timedOut = nil
+			default:
+				fmt.Fprintln(os.Stderr, "Previous evaluation still running, skipping this refresh")
+			}
+		}
+
+		if timedOut == nil {
+			if evaluations%watchFullEvaluationEvery == 0 {
+				invalidate()
+			}
+			first := evaluations == 1
+			timeout := refreshTimeout
+			if first {
+				timeout = loadTimeout
+			}
+			var expired <-chan time.Time
+			if timeout > 0 {
+				expired = time.After(timeout)
+			}
+
+			done := make(chan []tui.RuleResult, 1)
+			go func() { done <- evaluate(first) }()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-expired:
+				if first {
+					return fmt.Errorf("first evaluation timed out after %s", timeout)
+				}
+				fmt.Fprintf(os.Stderr, "Evaluation timed out after %s, skipping it\n", timeout)
+				timedOut = done
+			case results := <-done:
+				line, err := tui.FormatRulesJSONLine(results, namespace, appLabel, time.Now())
+				if err != nil {
+					return err
+				}
+				if _, err := out.WriteString(line); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// summaryLine renders one dense line with the state of each resource category of an app, e.g.
+// "my-app: Deployment: 3/3 ready | Service: 2 endpoints | Pods: 3 Running | Rules: 4/6 | KrakenD: referenced"
+func summaryLine(clientset kubernetes.Interface, namespace, appName, labelSelector, krakendMap, krakendLabel string,
+	results []tui.RuleResult) string {
+	var parts []string
+
+	if deployment, err := k.GetDeployment(clientset, namespace, appName); err != nil {
+		parts = append(parts, "Deployment: not found")
+	} else {
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		parts = append(parts, fmt.Sprintf("Deployment: %d/%d ready", deployment.Status.ReadyReplicas, desired))
+	}
+
+	if _, err := k.GetService(clientset, namespace, appName); err != nil {
+		parts = append(parts, "Service: not found")
+	} else if slices, err := k.GetServiceEndpointSlices(clientset, namespace, appName); err != nil {
+		parts = append(parts, "Service: endpoints unknown")
+	} else {
+		ready := 0
+		for _, slice := range slices {
+			for _, endpoint := range slice.Endpoints {
+				if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+					ready++
+				}
+			}
+		}
+		parts = append(parts, fmt.Sprintf("Service: %d endpoints", ready))
+	}
+
+	if pods, err := k.GetPodsByLabel(clientset, namespace, labelSelector); err != nil || len(pods) == 0 {
+		parts = append(parts, "Pods: none")
+	} else {
+		phases := make(map[string]int)
+		for _, pod := range pods {
+			// A pod without a phase yet hasn't been scheduled
+			phase := pod.Status.Phase
+			if phase == "" {
+				phase = corev1.PodPending
+			}
+			phases[string(phase)]++
+		}
+		var counts []string
+		for _, phase := range []corev1.PodPhase{corev1.PodRunning, corev1.PodPending, corev1.PodSucceeded, corev1.PodFailed, corev1.PodUnknown} {
+			if count := phases[string(phase)]; count > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", count, phase))
+			}
+		}
+		parts = append(parts, "Pods: "+strings.Join(counts, ", "))
+	}
+
+	passed := 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		}
+	}
+	parts = append(parts, fmt.Sprintf("Rules: %d/%d", passed, len(results)))
+
+	if krakendMap, err := resolveKrakenDMap(clientset, namespace, krakendMap, krakendLabel); err != nil {
+		parts = append(parts, "KrakenD: unknown")
+	} else if references, err := tui.KrakenDBackendReferences(clientset, namespace, krakendMap, appName); err != nil {
+		parts = append(parts, "KrakenD: unknown")
+	} else if len(references) == 0 {
+		parts = append(parts, "KrakenD: not referenced")
+	} else {
+		parts = append(parts, "KrakenD: referenced")
+	}
+
+	return fmt.Sprintf("%s: %s", appName, strings.Join(parts, " | "))
+}
+
+// resolveKrakenDMap returns the name of the KrakenD ConfigMap to check: the one selected by the
+// -krakend-label selector when set, looked up again each time as its name changes every deploy, or else -krakend-map
+func resolveKrakenDMap(clientset kubernetes.Interface, namespace, krakendMap, krakendLabel string) (string, error) {
+	if krakendLabel == "" {
+		return krakendMap, nil
+	}
+	return tui.FindKrakenDConfigMap(clientset, namespace, krakendLabel)
+}
+
+// krakendMapDescription describes the KrakenD ConfigMap lookup, for the parameters and the panel title
+func krakendMapDescription(krakendMap, krakendLabel string) string {
+	if krakendLabel != "" {
+		return "label " + krakendLabel
+	}
+	return krakendMap
+}
+
+// readAppsFile reads the app labels of a multi-app scan, one per line, skipping blank lines and # comments
+func readAppsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading apps file %s: %v", path, err)
+	}
+	var apps []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			apps = append(apps, line)
+		}
+	}
+	return apps, nil
+}
+
+// batchTarget is one app of a sweep read from stdin
+type batchTarget struct {
+	namespace, label string
+}
+
+// stdinIsBatchInput reports whether stdin is a pipe or a file rather than a terminal, as in
+// "cat targets.txt | k8s-rules-viewer -output json". A /dev/null stdin (e.g. under cron) doesn't count.
+func stdinIsBatchInput() bool {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+// readBatchTargets reads the targets of a sweep, one "namespace label" pair per line (a lone label is
+// looked up in defaultNamespace), skipping blank lines and # comments
+func readBatchTargets(r io.Reader, defaultNamespace string) ([]batchTarget, error) {
+	var targets []batchTarget
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch fields := strings.Fields(line); len(fields) {
+		case 1:
+			targets = append(targets, batchTarget{namespace: defaultNamespace, label: fields[0]})
+		case 2:
+			targets = append(targets, batchTarget{namespace: fields[0], label: fields[1]})
+		default:
+			return nil, fmt.Errorf("invalid target on stdin line %d %q: use \"namespace label\"", lineNumber, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading targets from stdin: %v", err)
+	}
+	return targets, nil
+}
+
+// formatAppsReport renders the reports of a multi-app or multi-namespace scan in the requested output format
+func formatAppsReport(format string, reports []tui.RulesReport) (string, error) {
+	switch format {
+	case "csv":
+		return tui.FormatReportsCSV(reports)
+	case "json":
+		return tui.FormatReportsJSON(reports)
+	default:
+		return "", fmt.Errorf("unsupported output format %q for a multi-app or multi-namespace scan (use csv or json)", format)
+	}
+}
+
+// resultColor is the matrix cell color of a rule result: green when it passed, and otherwise
+// red, yellow or blue by severity
+func resultColor(result tui.RuleResult) tcell.Color {
+	switch {
+	case result.Passed:
+		return tcell.ColorGreen
+	case result.Severity == tui.SeverityWarning:
+		return tcell.ColorYellow
+	case result.Severity == tui.SeverityInfo:
+		return tcell.ColorBlue
+	}
+	return tcell.ColorRed
+}
+
+// renderAppsMatrix shows a multi-app scan as a table with one row per app and one column per rule,
+// and the full compliance report of the selected app below it
+func renderAppsMatrix(app *tview.Application, namespace string, reports []tui.RulesReport, symbols tui.StatusSymbols,
+	rulesVerbosity string) {
+	// Columns are every rule seen, in evaluation order; rules only some apps evaluate show "-" elsewhere
+	var ruleNames []string
+	seen := make(map[string]bool)
+	for _, report := range reports {
+		for _, result := range report.Results {
+			if !seen[result.Name] {
+				seen[result.Name] = true
+				ruleNames = append(ruleNames, result.Name)
+			}
+		}
+	}
+
+	table := tview.NewTable().
+		SetFixed(1, 2).
+		SetSelectable(true, false)
+	table.SetBorder(true)
+	table.SetTitle(fmt.Sprintf("Rules Matrix - Namespace: %s (%d apps)", namespace, len(reports)))
+
+	headers := append([]string{"App", "Failed"}, ruleNames...)
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+	for i, report := range reports {
+		row := i + 1
+		failedColor := tcell.ColorGreen
+		if report.Failed > 0 {
+			failedColor = tcell.ColorRed
+		}
+		table.SetCell(row, 0, tview.NewTableCell(report.App))
+		table.SetCell(row, 1, tview.NewTableCell(strconv.Itoa(report.Failed)).
+			SetTextColor(failedColor).
+			SetAlign(tview.AlignCenter))
+
+		resultsByName := make(map[string]tui.RuleResult)
+		for _, result := range report.Results {
+			resultsByName[result.Name] = result
+		}
+		for j, name := range ruleNames {
+			cell := tview.NewTableCell("-").SetAlign(tview.AlignCenter)
+			if result, evaluated := resultsByName[name]; evaluated {
+				cell.SetText(tview.Escape(tui.ResultSymbol(symbols, result))).SetTextColor(resultColor(result))
+			}
+			table.SetCell(row, j+2, cell)
+		}
+	}
+
+	detail := tview.NewTextView()
+	detail.SetBorder(true)
+	detail.SetScrollable(true)
+	detail.SetDynamicColors(true)
+	showReport := func(row int) {
+		if row < 1 || row > len(reports) {
+			return
+		}
+		report := reports[row-1]
+		detail.SetTitle(fmt.Sprintf("Rules Compliance (%s)", report.App))
+		detail.SetText(tview.Escape(tui.FormatRulesCompliance(report.Results, namespace, symbols, rulesVerbosity)))
+		detail.ScrollToBeginning()
+	}
+	table.SetSelectionChangedFunc(func(row, _ int) {
+		showReport(row)
+	})
+	if len(reports) > 0 {
+		table.Select(1, 0)
+		showReport(1)
+	}
+
+	helpText := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText("Use arrow keys to select an app and scroll the rule columns. Press Ctrl+C to exit.")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(detail, 0, 1, false).
+		AddItem(helpText, 1, 0, false)
+	app.SetRoot(layout, true)
+	app.SetFocus(table)
+}
+
+// describeLabelKey returns a display name for a candidate label key
+func describeLabelKey(key string) string {
+	if key == "" {
+		return "<bare label>"
+	}
+	return key
+}
+
+// narrowLayoutWidth is the terminal width below which the detail panels are stacked vertically
+const narrowLayoutWidth = 120
+
+// namespaceWarningsLimit is how many namespace-wide warning events the dashboard shows
+const namespaceWarningsLimit = 10
+
+// namespaceWarningsScrollback is how many warning events the Namespace Warnings panel keeps
+// while watching them with -watch-namespace-events
+const namespaceWarningsScrollback = 200
+
+// maxToastLines is how many new warning events a toast shows at once
+const maxToastLines = 3
+
+// eventToastDuration is how long a toast stays at the top of the dashboard
+const eventToastDuration = 5 * time.Second
+
+// panelLoadingText is shown in each dashboard panel until its data arrives
+const panelLoadingText = "Loading..."
+
+// dashboard is the single-app TUI. Its panels are filled in independently as their data arrives,
+// so a slow or failing fetch never holds up the others. Its methods must run on the UI goroutine.
+type dashboard struct {
+	app                                  *tview.Application
+	clientset                            kubernetes.Interface
+	appLabel, namespace, banner          string
+	describeCmd, containerPattern        string
+	panels                               []string
+	rulesChecklist                       bool
+	mainFlex, contentFlex                *tview.Flex
+	header, helpText                     *tview.TextView
+	deploymentView, serviceView, podView *tview.TextView
+	batchView, pvcView, krakendView      *tview.TextView
+	warningsView, toastView              *tview.TextView
+	rulesView, rulesFocus                tview.Primitive
+	hiddenPanels                         map[string]bool
+	focusableViews                       []tview.Primitive
+	currentFocus                         int
+	mainVisible                          bool
+	cancelScreen                         context.CancelFunc
+	labelSelector                        string
+	podNames                             []string
+	selectedPod                          int
+	// rawDetails renders the deployment and service panels without validation markers;
+	// loadDetails (re)fetches them in the current mode
+	rawDetails  bool
+	loadDetails func()
+	// toastGeneration tells a toast's dismiss timer whether a newer toast replaced it
+	toastGeneration int
+	// refresh fetches every panel again ('r'), tracking which rules flipped in ruleChanges
+	refresh     func(first bool)
+	ruleChanges *ruleChanges
+	// evaluateRules and formatRules re-run just the rules ('R') against the resolved label selector;
+	// rulesRunning is set while they do
+	evaluateRules func(labelSelector string) []tui.RuleResult
+	formatRules   func(results []tui.RuleResult) string
+	rulesRunning  bool
+	// rulesGeneration counts the rule evaluations started ('r', 'R'), so the results of one overtaken
+	// by a newer evaluation are dropped instead of marking flips against out-of-order results
+	rulesGeneration int
+	// redactor masks node names and addresses in the screens built outside the panel setters
+	redactor *k.Redactor
+}
+
+// ruleChangeRefreshes is for how many evaluations a rule that flipped keeps its marker
+const ruleChangeRefreshes = 3
+
+// ruleChanges tracks the pass/fail state of each rule across the evaluations of a dashboard,
+// to mark the rules that flipped since the previous one
+type ruleChanges struct {
+	previous map[string]bool
+	// markers holds the marker of each flipped rule and how many more evaluations it's shown for
+	markers map[string]ruleChangeMarker
+}
+
+type ruleChangeMarker struct {
+	text string
+	left int
+}
+
+// annotate records an evaluation and returns its results with the rules that flipped in one of the last
+// ruleChangeRefreshes evaluations marked at the end of their description, e.g. "[↑ now passing]"
+func (c *ruleChanges) annotate(results []tui.RuleResult) []tui.RuleResult {
+	for name, marker := range c.markers {
+		if marker.left--; marker.left == 0 {
+			delete(c.markers, name)
+		} else {
+			c.markers[name] = marker
+		}
+	}
+
+	current := make(map[string]bool, len(results))
+	annotated := make([]tui.RuleResult, len(results))
+	for i, result := range results {
+		current[result.Name] = result.Passed
+		if passed, seen := c.previous[result.Name]; seen && passed != result.Passed {
+			text := "↓ now failing"
+			if result.Passed {
+				text = "↑ now passing"
+			}
+			c.markers[result.Name] = ruleChangeMarker{text: text, left: ruleChangeRefreshes}
+		}
+		annotated[i] = result
+		if marker, ok := c.markers[result.Name]; ok {
+			annotated[i].Description += fmt.Sprintf(" [%s]", marker.text)
+		}
+	}
+	c.previous = current
+	return annotated
+}
+
+// newPanelView creates a scrollable, bordered panel showing the loading text
+func newPanelView(title string) *tview.TextView {
+	view := tview.NewTextView()
+	view.SetBorder(true)
+	view.SetTitle(title)
+	view.SetText(panelLoadingText)
+	view.SetScrollable(true)
+	return view
+}
+
+// renderTUI shows the dashboard with every panel loading; the caller fills them in with the setters
+func renderTUI(app *tview.Application, appLabel, namespace, krakendMap, describeCmd, containerPattern string,
+	clientset kubernetes.Interface, banner string, rulesChecklist bool, panels []string,
+	evaluateRules func(labelSelector string) []tui.RuleResult, formatRules func(results []tui.RuleResult) string) *dashboard {
+	d := &dashboard{
+		evaluateRules:    evaluateRules,
+		formatRules:      formatRules,
+		app:              app,
+		clientset:        clientset,
+		appLabel:         appLabel,
+		namespace:        namespace,
+		banner:           banner,
+		describeCmd:      describeCmd,
+		containerPattern: containerPattern,
+		panels:           panels,
+		rulesChecklist:   rulesChecklist,
+		mainFlex:         tview.NewFlex().SetDirection(tview.FlexRow),
+		// Create content layout (deployment, service, pod info displayed side by side)
+		contentFlex: tview.NewFlex().SetDirection(tview.FlexColumn),
+		// Jobs & CronJobs and PVC panels are only shown for apps that use them
+		hiddenPanels: map[string]bool{"jobs": true, "pvc": true},
+		mainVisible:  true,
+		ruleChanges:  &ruleChanges{markers: make(map[string]ruleChangeMarker)},
+	}
+
+	// Add the header (title) with dynamic parameters and any connection warning
+	d.header = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	d.SetClusterInfo(panelLoadingText)
+
+	d.deploymentView = newPanelView("Deployment Details")
+	d.serviceView = newPanelView("Service Details")
+	d.serviceView.SetDynamicColors(true)
+	d.podView = newPanelView("Pod Monitoring")
+	d.podView.SetDynamicColors(true)
+	d.podView.SetRegions(true)
+	d.batchView = newPanelView("Jobs & CronJobs")
+	d.pvcView = newPanelView("Persistent Volume Claims")
+	d.krakendView = newPanelView(fmt.Sprintf("Krakend Config Check (%s)", krakendMap))
+	d.warningsView = newPanelView(fmt.Sprintf("Namespace Warnings (last %d)", namespaceWarningsLimit))
+	d.warningsView.SetDynamicColors(true)
+	d.toastView = tview.NewTextView().SetDynamicColors(true)
+	d.toastView.SetBackgroundColor(tcell.ColorDarkRed)
+	// Rule descriptions and remediations are escaped, e.g. the ["ALL"] of a capabilities fix isn't a region tag
+	rulesTextView := newPanelView("Rules Compliance")
+	rulesTextView.SetDynamicColors(true)
+	d.rulesView, d.rulesFocus = rulesTextView, rulesTextView
+
+	// Add help text at the bottom
+	d.helpText = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText("Use Tab to switch focus between panels. Use arrow keys to scroll content. [ ] select pod, l logs, L follow newest pod logs, o open pod, T resource tree, w wrap, v raw/validated details, r refresh, R re-run rules. Press Ctrl+C to exit.")
+
+	// Stack the detail panels on narrow terminals, where side-by-side columns wrap badly
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		width, _ := screen.Size()
+		if width < narrowLayoutWidth {
+			d.contentFlex.SetDirection(tview.FlexRow)
+		} else {
+			d.contentFlex.SetDirection(tview.FlexColumn)
+		}
+		return false
+	})
+
+	d.layout()
+
+	// Set the root layout and render the TUI
+	app.SetRoot(d.mainFlex, true)
+	app.SetInputCapture(d.handleKey)
+	return d
+}
+
+// dashboardPanel is a panel of the dashboard: what is laid out and what takes focus in it
+type dashboardPanel struct {
+	view, focus tview.Primitive
+}
+
+// panelViews returns the panels by their -panels name
+func (d *dashboard) panelViews() map[string]dashboardPanel {
+	return map[string]dashboardPanel{
+		"deployment": {d.deploymentView, d.deploymentView},
+		"service":    {d.serviceView, d.serviceView},
+		"pods":       {d.podView, d.podView},
+		"jobs":       {d.batchView, d.batchView},
+		"pvc":        {d.pvcView, d.pvcView},
+		"rules":      {d.rulesView, d.rulesFocus},
+		"krakend":    {d.krakendView, d.krakendView},
+		"warnings":   {d.warningsView, d.warningsView},
+	}
+}
+
+// focusedPanel returns the -panels name of the focused panel, "" when none is
+func (d *dashboard) focusedPanel() string {
+	if len(d.focusableViews) == 0 {
+		return ""
+	}
+	focused := d.focusableViews[d.currentFocus]
+	for name, p := range d.panelViews() {
+		if p.focus == focused {
+			return name
+		}
+	}
+	return ""
+}
+
+// focusPanel focuses the named panel when it is shown
+func (d *dashboard) focusPanel(name string) {
+	p, found := d.panelViews()[name]
+	if !found {
+		return
+	}
+	if index := slices.Index(d.focusableViews, p.focus); index >= 0 {
+		d.currentFocus = index
+		if d.mainVisible {
+			d.app.SetFocus(d.focusableViews[index])
+		}
+	}
+}
+
+// sessionState returns what the next launch restores of the dashboard
+func (d *dashboard) sessionState() sessionState {
+	showTimestamps := tui.LogTimestampsShown()
+	return sessionState{
+		Namespace:     d.namespace,
+		Label:         d.appLabel,
+		FocusedPanel:  d.focusedPanel(),
+		RawDetails:    d.rawDetails,
+		LogTimestamps: &showTimestamps,
+	}
+}
+
+// layout (re)builds the dashboard from the selected panels in the -panels order, the detail panels
+// sharing the row placed where the first of them is listed, keeping the focused panel focused
+func (d *dashboard) layout() {
+	panelViews := d.panelViews()
+
+	var focused tview.Primitive
+	if len(d.focusableViews) > 0 {
+		focused = d.focusableViews[d.currentFocus]
+	}
+
+	d.mainFlex.Clear()
+	d.contentFlex.Clear()
+	d.mainFlex.AddItem(d.header, 3, 0, false)
+	if toast := d.toastView.GetText(false); toast != "" {
+		d.mainFlex.AddItem(d.toastView, strings.Count(toast, "\n")+1, 0, false)
+	}
+
+	// Keep the Tab order following the panels from top to bottom and left to right
+	var focusableViews, detailFocus []tview.Primitive
+	contentIndex := -1
+	for _, name := range d.panels {
+		p := panelViews[name]
+		if d.hiddenPanels[name] {
+			continue
+		}
+		if !detailPanels[name] {
+			d.mainFlex.AddItem(p.view, 0, 1, true)
+			focusableViews = append(focusableViews, p.focus)
+			continue
+		}
+		if contentIndex < 0 {
+			d.mainFlex.AddItem(d.contentFlex, 0, 1, true)
+			contentIndex = len(focusableViews)
+		}
+		d.contentFlex.AddItem(p.view, 0, 1, true)
+		detailFocus = append(detailFocus, p.focus)
+	}
+	if contentIndex >= 0 {
+		focusableViews = slices.Insert(focusableViews, contentIndex, detailFocus...)
+	}
+	if len(focusableViews) == 0 {
+		emptyTextView := tview.NewTextView().
+			SetTextAlign(tview.AlignCenter).
+			SetText("None of the panels selected with -panels has anything to show for this app.")
+		d.mainFlex.AddItem(emptyTextView, 0, 1, true)
+		focusableViews = append(focusableViews, emptyTextView)
+	}
+	d.mainFlex.AddItem(d.helpText, 1, 0, false)
+
+	d.focusableViews = focusableViews
+	d.currentFocus = max(slices.Index(focusableViews, focused), 0)
+	if d.mainVisible {
+		d.app.SetFocus(focusableViews[d.currentFocus])
+	}
+}
+
+// SetClusterInfo shows the cluster summary in the header
+func (d *dashboard) SetClusterInfo(clusterInfo string) {
+	headerText := fmt.Sprintf("k8s-viewer-rules - Label: %s - Namespace: %s\n%s",
+		d.appLabel, d.namespace, tview.Escape(clusterInfo))
+	if d.banner != "" {
+		headerText += fmt.Sprintf("\n[red::b]%s[-:-:-]", tview.Escape(d.banner))
+	}
+	d.header.SetText(headerText)
+}
+
+// SetDeployment fills in the Deployment panel
+func (d *dashboard) SetDeployment(info string) {
+	d.deploymentView.SetText(info)
+}
+
+// SetService fills in the Service panel
+func (d *dashboard) SetService(info string) {
+	d.serviceView.SetText(info)
+}
+
+// SetPods fills in the pod panel and enables the pod keys (select, logs, open, tree)
+func (d *dashboard) SetPods(labelSelector string, podNames []string, podInfo string) {
+	d.labelSelector = labelSelector
+	d.podNames = podNames
+	d.selectedPod = 0
+	d.podView.SetTitle(fmt.Sprintf("Pod Monitoring (label: %s)", labelSelector))
+	d.podView.SetText(podInfo)
+	if len(podNames) > 0 {
+		d.podView.Highlight("pod-0")
+	}
+}
+
+// SetBatch fills in the Jobs & CronJobs panel, shown only when there are some
+func (d *dashboard) SetBatch(info string) {
+	d.setOptionalPanel("jobs", d.batchView, info)
+}
+
+// SetPVC fills in the PersistentVolumeClaims panel, shown only for stateful apps
+func (d *dashboard) SetPVC(info string) {
+	d.setOptionalPanel("pvc", d.pvcView, info)
+}
+
+// setOptionalPanel fills in a panel hidden while it has nothing to show
+func (d *dashboard) setOptionalPanel(name string, view *tview.TextView, info string) {
+	view.SetText(info)
+	if d.hiddenPanels[name] != (info == "") {
+		d.hiddenPanels[name] = info == ""
+		d.layout()
+	}
+}
+
+// SetRules fills in the rules section, either the report or the checklist of failing rules
+func (d *dashboard) SetRules(results []tui.RuleResult, rulesCompliance string) {
+	if !d.rulesChecklist {
+		d.rulesView.(*tview.TextView).SetText(rulesCompliance)
+		return
+	}
+	d.rulesView, d.rulesFocus = newRulesChecklist(results)
+	d.layout()
+}
+
+// startRules returns the generation of a rule evaluation being started
+func (d *dashboard) startRules() int {
+	d.rulesGeneration++
+	return d.rulesGeneration
+}
+
+// showRules fills in the rules section with an evaluation, marking the rules that flipped since the previous one.
+// The results are dropped when a newer evaluation was started since this one.
+func (d *dashboard) showRules(generation int, results []tui.RuleResult) {
+	if generation != d.rulesGeneration {
+		return
+	}
+	results = d.ruleChanges.annotate(results)
+	d.SetRules(results, d.formatRules(results))
+}
+
+// spinnerFrames animate the rules section title while 'R' re-evaluates the rules
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerInterval is how often the spinner advances
+const spinnerInterval = 100 * time.Millisecond
+
+// titledPrimitive is a bordered panel, the rules text view or checklist
+type titledPrimitive interface {
+	GetTitle() string
+	SetTitle(title string) *tview.Box
+}
+
+// rerunRules evaluates the rules again ('R') without fetching the other panels, spinning in the title
+// of the rules section meanwhile. It does nothing before the label selector is resolved or while a
+// re-evaluation is still running.
+func (d *dashboard) rerunRules() {
+	if d.rulesRunning || d.labelSelector == "" || d.evaluateRules == nil {
+		return
+	}
+	d.rulesRunning = true
+	generation := d.startRules()
+	labelSelector := d.labelSelector
+	done := make(chan []tui.RuleResult, 1)
+	go func() { done <- d.evaluateRules(labelSelector) }()
+
+	// The title is restored on the view spun, which a checklist evaluation replaces anyway
+	view, _ := d.rulesView.(titledPrimitive)
+	title := ""
+	if view != nil {
+		title = view.GetTitle()
+		view.SetTitle(fmt.Sprintf("%s %s re-evaluating", title, spinnerFrames[0]))
+	}
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 1; ; frame++ {
+			select {
+			case results := <-done:
+				d.app.QueueUpdateDraw(func() {
+					d.rulesRunning = false
+					if view != nil {
+						view.SetTitle(title)
+					}
+					d.showRules(generation, results)
+				})
+				return
+			case <-ticker.C:
+				spinner := spinnerFrames[frame%len(spinnerFrames)]
+				d.app.QueueUpdateDraw(func() {
+					if view != nil && d.rulesRunning {
+						view.SetTitle(fmt.Sprintf("%s %s re-evaluating", title, spinner))
+					}
+				})
+			}
+		}
+	}()
+}
+
+// SetKrakend fills in the KrakenD panel
+func (d *dashboard) SetKrakend(info string) {
+	d.krakendView.SetText(info)
+}
+
+// SetNamespaceWarnings fills in the Namespace Warnings panel
+func (d *dashboard) SetNamespaceWarnings(info string) {
+	d.warningsView.SetText(info)
+}
+
+// SetWarningsScrollback fills in the Namespace Warnings panel with the warnings kept while watching them
+func (d *dashboard) SetWarningsScrollback(info string) {
+	d.warningsView.SetTitle(fmt.Sprintf("Namespace Warnings (watching, last %d)", namespaceWarningsScrollback))
+	d.warningsView.SetText(info)
+}
+
+// ShowToast shows a notification above the panels, dismissed after eventToastDuration
+// unless a newer one replaced it first
+func (d *dashboard) ShowToast(text string) {
+	d.toastGeneration++
+	generation := d.toastGeneration
+	d.toastView.SetText(strings.TrimRight(text, "\n"))
+	d.layout()
+	time.AfterFunc(eventToastDuration, func() {
+		d.app.QueueUpdateDraw(func() {
+			if d.toastGeneration == generation {
+				d.toastView.SetText("")
+				d.layout()
+			}
+		})
+	})
+}
+
+// promptForApp replaces the dashboard with a form to pick another namespace, label and label keys
+// when no pod matched, pre-filled with the current values. Search reloads the dashboard with the
+// new values; Cancel (or Esc) returns to the current dashboard and its diagnosis.
+func (d *dashboard) promptForApp(labelKeys string, reload func(namespace, appLabel string, candidateKeys []string)) {
+	form := tview.NewForm().
+		AddInputField("Namespace", d.namespace, 40, nil, nil).
+		AddInputField("Label", d.appLabel, 40, nil, nil).
+		AddInputField("Label keys", labelKeys, 60, nil, nil)
+	form.AddButton("Search", func() {
+		namespace := strings.TrimSpace(form.GetFormItemByLabel("Namespace").(*tview.InputField).GetText())
+		appLabel := strings.TrimSpace(form.GetFormItemByLabel("Label").(*tview.InputField).GetText())
+		keys := form.GetFormItemByLabel("Label keys").(*tview.InputField).GetText()
+		if namespace == "" || appLabel == "" {
+			return
+		}
+		reload(namespace, appLabel, parseLabelKeys(keys))
+	})
+	form.AddButton("Cancel", d.showMain)
+	form.SetBorder(true)
+	form.SetTitle(fmt.Sprintf(" No pods found for %q in namespace %s - search again (Esc: back to the dashboard) ",
+		d.appLabel, d.namespace))
+
+	d.showScreen()
+	d.app.SetRoot(form, true)
+}
+
+// showScreen replaces the dashboard with another screen (tree, logs), returning the context
+// cancelled when going back so the screen's background work (log streams) stops
+func (d *dashboard) showScreen() context.Context {
+	d.mainVisible = false
+	var screenCtx context.Context
+	screenCtx, d.cancelScreen = context.WithCancel(context.Background())
+	return screenCtx
+}
+
+// showMain returns to the dashboard
+func (d *dashboard) showMain() {
+	if d.cancelScreen != nil {
+		d.cancelScreen()
+		d.cancelScreen = nil
+	}
+	d.mainVisible = true
+	d.app.SetRoot(d.mainFlex, true)
+	d.app.SetFocus(d.focusableViews[d.currentFocus])
+}
+
+// handleKey is the input capture of the application: Tab navigation between panels and the pod keys
+func (d *dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	// w toggles line wrapping of the focused text view, on the dashboard and in the log view
+	if event.Key() == tcell.KeyRune && event.Rune() == 'w' {
+		if view, ok := d.app.GetFocus().(*tview.TextView); ok {
+			tui.ToggleWrap(view)
+			return nil
+		}
+	}
+
+	if !d.mainVisible {
+		// Esc returns from the tree or log screens to the dashboard
+		if event.Key() == tcell.KeyEscape {
+			d.showMain()
+			return nil
+		}
+		return event
+	}
+
+	if event.Key() == tcell.KeyTab {
+		// Move to next focusable view
+		d.currentFocus = (d.currentFocus + 1) % len(d.focusableViews)
+		d.app.SetFocus(d.focusableViews[d.currentFocus])
+		return nil
+	} else if event.Key() == tcell.KeyBacktab {
+		// Move to previous focusable view
+		d.currentFocus = (d.currentFocus - 1 + len(d.focusableViews)) % len(d.focusableViews)
+		d.app.SetFocus(d.focusableViews[d.currentFocus])
+		return nil
+	}
+
+	// The tree needs the label selector, resolved along with the pods
+	if event.Key() == tcell.KeyRune && event.Rune() == 'T' && d.labelSelector != "" {
+		showResourceTree(d.showScreen(), d.app, d.clientset, d.namespace, d.appLabel, d.labelSelector, d.redactor)
+		return nil
+	}
+
+	// Switch the deployment and service panels between the validated and the raw rendering
+	if event.Key() == tcell.KeyRune && event.Rune() == 'v' && d.loadDetails != nil {
+		d.rawDetails = !d.rawDetails
+		d.loadDetails()
+		return nil
+	}
+
+	// Fetch every panel again, marking the rules that flipped since the previous evaluation
+	if event.Key() == tcell.KeyRune && event.Rune() == 'r' && d.refresh != nil {
+		d.refresh(false)
+		return nil
+	}
+
+	// Re-run only the rules, for quick feedback while fixing a violation
+	if event.Key() == tcell.KeyRune && event.Rune() == 'R' {
+		d.rerunRules()
+		return nil
+	}
+
+	// Follow the logs of the newest pod matching the label selector, across pod replacements
+	if event.Key() == tcell.KeyRune && event.Rune() == 'L' && d.labelSelector != "" {
+		tui.FollowLogsByLabel(d.showScreen(), d.clientset, d.namespace, d.labelSelector, d.containerPattern, d.app)
+		return nil
+	}
+
+	if event.Key() == tcell.KeyRune && len(d.podNames) > 0 {
+		switch event.Rune() {
+		case '[', ']':
+			// Select the previous/next pod in the pod panel
+			if event.Rune() == ']' {
+				d.selectedPod = (d.selectedPod + 1) % len(d.podNames)
+			} else {
+				d.selectedPod = (d.selectedPod - 1 + len(d.podNames)) % len(d.podNames)
+			}
+			d.podView.Highlight(fmt.Sprintf("pod-%d", d.selectedPod)).ScrollToHighlight()
+			return nil
+		case 'o':
+			runPodCommand(d.app, d.describeCmd, d.namespace, d.podNames[d.selectedPod])
+			return nil
+		case 'l':
+			// Stream the logs of the selected pod
+			tui.DisplayLogsInTUI(d.showScreen(), d.clientset, d.namespace, d.podNames, d.selectedPod, d.containerPattern, d.app)
+			return nil
+		}
+	}
+	return event
+}
+
+// containerRef identifies a container node in the resource tree, along with
+// the app's pods so the log view can switch between them
+type containerRef struct {
+	podNames      []string
+	podIndex      int
+	containerName string
+}
+
+// showResourceTree replaces the dashboard with a tree of the app's resources.
+// Selecting a container opens its logs, any other node is expanded or collapsed.
+func showResourceTree(ctx context.Context, app *tview.Application, clientset kubernetes.Interface, namespace, appLabel, labelSelector string,
+	redactor *k.Redactor) {
+	root := tview.NewTreeNode(fmt.Sprintf("%s (namespace: %s)", appLabel, namespace)).
+		SetColor(tcell.ColorYellow)
+	root.AddChild(tview.NewTreeNode("Loading..."))
+
+	tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+	tree.SetBorder(true)
+	tree.SetTitle("Resource Tree (Enter: expand/collapse or open logs, Esc: back)")
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if ref, ok := node.GetReference().(containerRef); ok {
+			pattern := regexp.QuoteMeta(ref.containerName)
+			tui.DisplayLogsInTUI(ctx, clientset, namespace, ref.podNames, ref.podIndex, pattern, app)
+			return
+		}
+		node.SetExpanded(!node.IsExpanded())
+	})
+	app.SetRoot(tree, true)
+
+	// Fetch the resources without blocking the UI
+	go func() {
+		nodes := buildResourceTree(clientset, namespace, appLabel, labelSelector, redactor)
+		app.QueueUpdateDraw(func() {
+			root.ClearChildren()
+			for _, node := range nodes {
+				root.AddChild(node)
+			}
+		})
+	}()
+}
+
+// buildResourceTree builds Deployment → ReplicaSets → Pods → Containers and Service → Endpoints
+// nodes, linking pods to their ReplicaSets through ownerReferences. Node names and addresses are
+// masked by the redactor, as in the panels.
+func buildResourceTree(clientset kubernetes.Interface, namespace, appLabel, labelSelector string, redactor *k.Redactor) []*tview.TreeNode {
+	var nodes []*tview.TreeNode
+
+	pods, err := k.GetPodsByLabel(clientset, namespace, labelSelector)
+	if err != nil {
+		nodes = append(nodes, tview.NewTreeNode(fmt.Sprintf("Pods: %v", err)).SetColor(tcell.ColorRed))
+	}
+	podNames := make([]string, len(pods))
+	for i, pod := range pods {
+		podNames[i] = pod.Name
+	}
+	attached := make(map[string]bool)
+
+	// Deployment → ReplicaSets → Pods → Containers
+	deployment, err := k.GetDeployment(clientset, namespace, appLabel)
+	if err != nil {
+		nodes = append(nodes, tview.NewTreeNode(fmt.Sprintf("Deployment: %v", err)).SetColor(tcell.ColorRed))
+	} else {
+		deploymentNode := tview.NewTreeNode(fmt.Sprintf("Deployment: %s (%d/%d ready)",
+			deployment.Name, deployment.Status.ReadyReplicas, deployment.Status.Replicas)).
+			SetColor(tcell.ColorGreen)
+
+		replicaSets, err := k.GetReplicaSetsForDeployment(clientset, deployment)
+		if err != nil {
+			deploymentNode.AddChild(tview.NewTreeNode(fmt.Sprintf("ReplicaSets: %v", err)).SetColor(tcell.ColorRed))
+		}
+		for _, rs := range replicaSets {
+			rsNode := tview.NewTreeNode(fmt.Sprintf("ReplicaSet: %s (%d/%d ready)",
+				rs.Name, rs.Status.ReadyReplicas, rs.Status.Replicas))
+			for i := range pods {
+				if owner := metav1.GetControllerOf(&pods[i]); owner != nil && owner.UID == rs.UID {
+					rsNode.AddChild(podTreeNode(podNames, i, &pods[i], redactor))
+					attached[pods[i].Name] = true
+				}
+			}
+			// Collapse old ReplicaSets that no longer run any pods
+			rsNode.SetExpanded(len(rsNode.GetChildren()) > 0)
+			deploymentNode.AddChild(rsNode)
+		}
+		nodes = append(nodes, deploymentNode)
+	}
+
+	// Pods matching the label but not owned by the deployment
+	otherPods := tview.NewTreeNode("Other Pods")
+	for i := range pods {
+		if !attached[pods[i].Name] {
+			otherPods.AddChild(podTreeNode(podNames, i, &pods[i], redactor))
+		}
+	}
+	if len(otherPods.GetChildren()) > 0 {
+		nodes = append(nodes, otherPods)
+	}
+
+	// Service → Endpoints
+	service, err := k.GetService(clientset, namespace, appLabel)
+	if err != nil {
+		nodes = append(nodes, tview.NewTreeNode(fmt.Sprintf("Service: %v", err)).SetColor(tcell.ColorRed))
+		return nodes
+	}
+	serviceNode := tview.NewTreeNode(redactor.Redact(fmt.Sprintf("Service: %s (%s, %s)",
+		service.Name, service.Spec.Type, service.Spec.ClusterIP))).
+		SetColor(tcell.ColorGreen)
+
+	slices, err := k.GetServiceEndpointSlices(clientset, namespace, service.Name)
+	if err != nil {
+		serviceNode.AddChild(tview.NewTreeNode(fmt.Sprintf("Endpoints: %v", err)).SetColor(tcell.ColorRed))
+	}
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			target := "-"
+			if endpoint.TargetRef != nil {
+				target = endpoint.TargetRef.Name
+			}
+			ready := endpoint.Conditions.Ready != nil && *endpoint.Conditions.Ready
+			color := tcell.ColorGreen
+			status := "ready"
+			if !ready {
+				color = tcell.ColorRed
+				status = "not ready"
+			}
+			serviceNode.AddChild(tview.NewTreeNode(redactor.Redact(fmt.Sprintf("Endpoint: %s → %s (%s)",
+				strings.Join(endpoint.Addresses, ", "), target, status))).
+				SetColor(color))
+		}
+	}
+	if len(serviceNode.GetChildren()) == 0 {
+		serviceNode.AddChild(tview.NewTreeNode("No endpoints").SetColor(tcell.ColorRed))
+	}
+	nodes = append(nodes, serviceNode)
+
+	return nodes
+}
+
+// podTreeNode builds a pod node with one selectable child per container.
+// podNames lists all the app's pods and podIndex is the position of this pod in it.
+func podTreeNode(podNames []string, podIndex int, pod *corev1.Pod, redactor *k.Redactor) *tview.TreeNode {
+	color := tcell.ColorGreen
+	if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodSucceeded {
+		color = tcell.ColorRed
+	}
+	podNode := tview.NewTreeNode(redactor.Redact(fmt.Sprintf("Pod: %s (%s, node: %s)",
+		pod.Name, pod.Status.Phase, pod.Spec.NodeName))).
+		SetColor(color)
+
+	for _, container := range pod.Spec.Containers {
+		podNode.AddChild(tview.NewTreeNode(fmt.Sprintf("Container: %s (%s)", container.Name, container.Image)).
+			SetReference(containerRef{podNames: podNames, podIndex: podIndex, containerName: container.Name}))
+	}
+	return podNode
+}
+
+// runPodCommand suspends the TUI and runs an external command (kubectl, k9s, ...) for a pod
+func runPodCommand(app *tview.Application, commandTemplate, namespace, podName string) {
+	replacer := strings.NewReplacer("{pod}", podName, "{namespace}", namespace)
+	args := strings.Fields(replacer.Replace(commandTemplate))
+	if len(args) == 0 {
+		return
+	}
+
+	app.Suspend(func() {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", args[0], err)
+		}
+
+		// Wait so the output can be read before the TUI takes over the screen again
+		fmt.Print("\nPress Enter to return to k8s-rules-viewer...")
+		bufio.NewReader(os.Stdin).ReadString('\n')
 	})
 }
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+)
+
+// deprecatedAPI describes when an apiVersion was deprecated and removed (Kubernetes 1.x minor versions)
+type deprecatedAPI struct {
+	deprecatedIn uint
+	removedIn    uint
+	replacement  string
+}
+
+// deprecatedAPIs maps the deprecated apiVersions of the kinds an app typically relies on
+// to their deprecation, removal and replacement
+var deprecatedAPIs = map[string]deprecatedAPI{
+	"extensions/v1beta1":                   {deprecatedIn: 8, removedIn: 16, replacement: "apps/v1 or networking.k8s.io/v1"},
+	"apps/v1beta1":                         {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"apps/v1beta2":                         {deprecatedIn: 9, removedIn: 16, replacement: "apps/v1"},
+	"networking.k8s.io/v1beta1":            {deprecatedIn: 19, removedIn: 22, replacement: "networking.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1":    {deprecatedIn: 17, removedIn: 22, replacement: "rbac.authorization.k8s.io/v1"},
+	"admissionregistration.k8s.io/v1beta1": {deprecatedIn: 16, removedIn: 22, replacement: "admissionregistration.k8s.io/v1"},
+	"apiextensions.k8s.io/v1beta1":         {deprecatedIn: 16, removedIn: 22, replacement: "apiextensions.k8s.io/v1"},
+	"batch/v1beta1":                        {deprecatedIn: 21, removedIn: 25, replacement: "batch/v1"},
+	"policy/v1beta1":                       {deprecatedIn: 21, removedIn: 25, replacement: "policy/v1"},
+	"discovery.k8s.io/v1beta1":             {deprecatedIn: 21, removedIn: 25, replacement: "discovery.k8s.io/v1"},
+	"autoscaling/v2beta1":                  {deprecatedIn: 22, removedIn: 25, replacement: "autoscaling/v2"},
+	"autoscaling/v2beta2":                  {deprecatedIn: 23, removedIn: 26, replacement: "autoscaling/v2"},
+	"flowcontrol.apiserver.k8s.io/v1beta2": {deprecatedIn: 26, removedIn: 29, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	"flowcontrol.apiserver.k8s.io/v1beta3": {deprecatedIn: 29, removedIn: 32, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// ObjectAPIVersions returns the apiVersions an object was written with, as recorded by its
+// field managers and the kubectl last-applied-configuration annotation
+func ObjectAPIVersions(obj metav1.Object) []string {
+	var versions []string
+	seen := make(map[string]bool)
+	add := func(apiVersion string) {
+		if apiVersion != "" && !seen[apiVersion] {
+			seen[apiVersion] = true
+			versions = append(versions, apiVersion)
+		}
+	}
+
+	for _, entry := range obj.GetManagedFields() {
+		add(entry.APIVersion)
+	}
+	if lastApplied, exists := obj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]; exists {
+		var applied metav1.TypeMeta
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil {
+			add(applied.APIVersion)
+		}
+	}
+	return versions
+}
+
+// FindDeprecatedAPIs checks the apiVersions the objects (keyed by "Kind/name") were written with
+// against the deprecation map, relative to the cluster version reported by the discovery client
+func FindDeprecatedAPIs(discoveryClient discovery.DiscoveryInterface, objects map[string]metav1.Object) ([]string, error) {
+	serverVersion, err := CachedServerVersion(discoveryClient)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving server version: %v", err)
+	}
+	clusterVersion, err := version.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing server version %q: %v", serverVersion.GitVersion, err)
+	}
+
+	var findings []string
+	for name, obj := range objects {
+		for _, apiVersion := range ObjectAPIVersions(obj) {
+			api, deprecated := deprecatedAPIs[apiVersion]
+			if !deprecated || clusterVersion.Minor()+1 < api.deprecatedIn {
+				continue
+			}
+			status := fmt.Sprintf("deprecated in 1.%d, removed in 1.%d", api.deprecatedIn, api.removedIn)
+			if clusterVersion.Minor() >= api.removedIn {
+				status = fmt.Sprintf("removed in 1.%d", api.removedIn)
+			}
+			findings = append(findings, fmt.Sprintf("%s uses %s (%s, use %s)", name, apiVersion, status, api.replacement))
+		}
+	}
+	sort.Strings(findings)
+	return findings, nil
+}
@@ -0,0 +1,108 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetNodes returns all the nodes of the cluster
+func GetNodes(clientset kubernetes.Interface) ([]corev1.Node, error) {
+	nodes, err := listAll(metav1.ListOptions{}, func(opts metav1.ListOptions) ([]corev1.Node, string, error) {
+		list, err := clientset.CoreV1().Nodes().List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving nodes: %v", err)
+	}
+	return nodes, nil
+}
+
+// GetAppNamespaces returns the namespaces, sorted, holding a Deployment or a Service with the given name
+func GetAppNamespaces(clientset kubernetes.Interface, name string) ([]string, error) {
+	opts := metav1.ListOptions{FieldSelector: "metadata.name=" + name}
+	seen := make(map[string]bool)
+
+	deployments, err := listAll(opts, func(opts metav1.ListOptions) ([]metav1.ObjectMeta, string, error) {
+		list, err := clientset.AppsV1().Deployments(metav1.NamespaceAll).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		metas := make([]metav1.ObjectMeta, len(list.Items))
+		for i, item := range list.Items {
+			metas[i] = item.ObjectMeta
+		}
+		return metas, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deployments: %v", err)
+	}
+	services, err := listAll(opts, func(opts metav1.ListOptions) ([]metav1.ObjectMeta, string, error) {
+		list, err := clientset.CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		metas := make([]metav1.ObjectMeta, len(list.Items))
+		for i, item := range list.Items {
+			metas[i] = item.ObjectMeta
+		}
+		return metas, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving services: %v", err)
+	}
+
+	var namespaces []string
+	for _, meta := range append(deployments, services...) {
+		// Fake clients (-manifests) ignore field selectors, so check the name too
+		if meta.Name == name && !seen[meta.Namespace] {
+			seen[meta.Namespace] = true
+			namespaces = append(namespaces, meta.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// IsNodeReady reports whether the node's Ready condition is true
+func IsNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// GetClusterInfo fetches the cluster's Kubernetes version and node readiness summary
+func GetClusterInfo(clientset kubernetes.Interface) string {
+	version := "unknown"
+	if serverVersion, err := CachedServerVersion(clientset.Discovery()); err == nil {
+		version = serverVersion.GitVersion
+	}
+
+	nodes, err := GetNodes(clientset)
+	if err != nil {
+		return fmt.Sprintf("Kubernetes %s - Nodes: %v", version, err)
+	}
+
+	ready := 0
+	for i := range nodes {
+		if IsNodeReady(&nodes[i]) {
+			ready++
+		}
+	}
+
+	info := fmt.Sprintf("Kubernetes %s - Nodes: %d/%d ready", version, ready, len(nodes))
+	if ready < len(nodes) {
+		info += fmt.Sprintf(" (%d not ready)", len(nodes)-ready)
+	}
+	return info
+}
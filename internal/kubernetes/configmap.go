@@ -0,0 +1,25 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetConfigMapsByLabel returns the ConfigMaps matching the given label selector
+func GetConfigMapsByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]corev1.ConfigMap, error) {
+	configMaps, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]corev1.ConfigMap, string, error) {
+		list, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving ConfigMaps: %v", err)
+	}
+	return configMaps, nil
+}
@@ -5,27 +5,47 @@ import (
 	"fmt"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-// GetDeploymentInfo fetches deployment details from the Kubernetes cluster
-func GetDeploymentInfo(clientset *kubernetes.Clientset, namespace, deploymentName string) string {
+// GetDeploymentInfo fetches deployment details from the Kubernetes cluster,
+// validating the required labels and the given required annotations, marked with the given symbols.
+// With RawSymbols the labels and annotations are listed as they are, without validation.
+// A ready count from pods without any readiness probe carries a caveat, as it only means the containers started,
+// also in the raw rendering.
+func GetDeploymentInfo(clientset kubernetes.Interface, namespace, deploymentName string, requiredAnnotations []string, symbols StatusSymbols) string {
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Sprintf("Error retrieving deployment: %v", err)
 	}
 
-	info := fmt.Sprintf("Name: %s\nNamespace: %s\nReplicas: %d/%d\nCreation Time: %s\nSelector: %v\n",
+	// The caveat is a fact about the ready count, so the raw rendering keeps it without the marker
+	readyCaveat := ""
+	if !hasReadinessProbe(deployment) {
+		readyCaveat = " [readiness is trivially true (no probe)]"
+		if !symbols.raw() {
+			readyCaveat = fmt.Sprintf(" [%s readiness is trivially true (no probe)]", symbols.Failure)
+		}
+	}
+
+	info := fmt.Sprintf("Name: %s\nNamespace: %s\nReplicas: %d/%d%s\nCreation Time: %s\nSelector: %v\n",
 		deployment.Name,
 		deployment.Namespace,
 		deployment.Status.ReadyReplicas,
 		deployment.Status.Replicas,
+		readyCaveat,
 		deployment.CreationTimestamp.String(),
 		deployment.Spec.Selector.MatchLabels)
 
-	// Add labels information with validation
-	if len(deployment.Labels) > 0 {
+	if symbols.raw() {
+		info += "Labels:\n"
+		for k, v := range deployment.Labels {
+			info += fmt.Sprintf("  %s: %s\n", k, v)
+		}
+	} else if len(deployment.Labels) > 0 {
+		// Add labels information with validation
 		labelStrings := []string{"Labels:"}
 		requiredLabels := []string{"app", "version"}
 
@@ -34,7 +54,7 @@ func GetDeploymentInfo(clientset *kubernetes.Clientset, namespace, deploymentNam
 			// Mark required labels
 			for _, reqLabel := range requiredLabels {
 				if k == reqLabel {
-					validation = "✓"
+					validation = symbols.Success
 					break
 				}
 			}
@@ -44,14 +64,98 @@ func GetDeploymentInfo(clientset *kubernetes.Clientset, namespace, deploymentNam
 		// Check for missing required labels
 		for _, reqLabel := range requiredLabels {
 			if _, exists := deployment.Labels[reqLabel]; !exists {
-				labelStrings = append(labelStrings, fmt.Sprintf("  %s: MISSING [✗]", reqLabel))
+				labelStrings = append(labelStrings, fmt.Sprintf("  %s: MISSING [%s]", reqLabel, symbols.Failure))
 			}
 		}
 
 		info += strings.Join(labelStrings, "\n") + "\n"
 	} else {
-		info += "Labels: None (Missing required labels: app, version) [✗]\n"
+		info += fmt.Sprintf("Labels: None (Missing required labels: app, version) [%s]\n", symbols.Failure)
+	}
+
+	// Add required annotations, which may live on the deployment or its pod template
+	if len(requiredAnnotations) > 0 {
+		annotationStrings := []string{"Required Annotations:"}
+		for _, annotation := range requiredAnnotations {
+			value, exists := deployment.Annotations[annotation]
+			if !exists {
+				value, exists = deployment.Spec.Template.Annotations[annotation]
+			}
+			if symbols.raw() {
+				if !exists {
+					value = "<unset>"
+				}
+				annotationStrings = append(annotationStrings, fmt.Sprintf("  %s: %s", annotation, value))
+			} else if exists {
+				annotationStrings = append(annotationStrings, fmt.Sprintf("  %s: %s [%s]", annotation, value, symbols.Success))
+			} else {
+				annotationStrings = append(annotationStrings, fmt.Sprintf("  %s: MISSING [%s]", annotation, symbols.Failure))
+			}
+		}
+		info += strings.Join(annotationStrings, "\n") + "\n"
 	}
 
 	return info
 }
+
+// hasReadinessProbe reports whether any app container of the deployment's pod template has a readiness
+// probe. A service mesh sidecar's probe says nothing about whether the app is ready.
+func hasReadinessProbe(deployment *appsv1.Deployment) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if !IsSidecarContainer(container.Name) && container.ReadinessProbe != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDeployment fetches a deployment object by name
+func GetDeployment(clientset kubernetes.Interface, namespace, deploymentName string) (*appsv1.Deployment, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deployment: %v", err)
+	}
+	return deployment, nil
+}
+
+// GetDeploymentsByLabel returns the deployments matching the given label selector
+func GetDeploymentsByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]appsv1.Deployment, error) {
+	deployments, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]appsv1.Deployment, string, error) {
+		list, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deployments: %v", err)
+	}
+	return deployments, nil
+}
+
+// GetReplicaSetsForDeployment returns the ReplicaSets controlled by the given deployment
+func GetReplicaSetsForDeployment(clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment selector: %v", err)
+	}
+
+	replicaSets, err := listAll(metav1.ListOptions{LabelSelector: selector.String()}, func(opts metav1.ListOptions) ([]appsv1.ReplicaSet, string, error) {
+		list, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving replicasets: %v", err)
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, rs := range replicaSets {
+		if metav1.IsControlledBy(&rs, deployment) {
+			owned = append(owned, rs)
+		}
+	}
+	return owned, nil
+}
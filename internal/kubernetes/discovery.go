@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// discoveryCache remembers the discovery results (server version, the resources of each group version)
+// for the session, as API availability rarely changes and discovery round-trips add up on every refresh.
+// The tool talks to one cluster at a time, so the results aren't keyed by client.
+var discoveryCache = struct {
+	sync.Mutex
+	serverVersion *version.Info
+	resources     map[string]*metav1.APIResourceList
+	// missing holds the group versions the server doesn't serve (NotFound), e.g. CRDs not installed
+	missing map[string]error
+}{
+	resources: make(map[string]*metav1.APIResourceList),
+	missing:   make(map[string]error),
+}
+
+// InvalidateDiscoveryCache forgets the cached discovery results, e.g. after reconnecting to the cluster
+func InvalidateDiscoveryCache() {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	discoveryCache.serverVersion = nil
+	discoveryCache.resources = make(map[string]*metav1.APIResourceList)
+	discoveryCache.missing = make(map[string]error)
+}
+
+// CachedServerVersion returns the cluster version, asking the server only the first time
+func CachedServerVersion(discoveryClient discovery.DiscoveryInterface) (*version.Info, error) {
+	discoveryCache.Lock()
+	cached := discoveryCache.serverVersion
+	discoveryCache.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	discoveryCache.Lock()
+	discoveryCache.serverVersion = serverVersion
+	discoveryCache.Unlock()
+	return serverVersion, nil
+}
+
+// CachedServerResourcesForGroupVersion returns the resources served for the group version, asking the
+// server only the first time. A NotFound error (group version not served) is cached too; other errors
+// (e.g. a timeout) are not, so the next call retries.
+func CachedServerResourcesForGroupVersion(discoveryClient discovery.DiscoveryInterface, groupVersion string) (*metav1.APIResourceList, error) {
+	discoveryCache.Lock()
+	resources, found := discoveryCache.resources[groupVersion]
+	missingErr, missing := discoveryCache.missing[groupVersion]
+	discoveryCache.Unlock()
+	if found {
+		return resources, nil
+	}
+	if missing {
+		return nil, missingErr
+	}
+
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	switch {
+	case err == nil:
+		discoveryCache.resources[groupVersion] = resources
+	case apierrors.IsNotFound(err):
+		discoveryCache.missing[groupVersion] = err
+	}
+	return resources, err
+}
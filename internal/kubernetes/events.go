@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetRecentEvents returns up to limit events in the namespace matching the field selector,
+// most recent first
+func GetRecentEvents(clientset kubernetes.Interface, namespace, fieldSelector string, limit int) ([]corev1.Event, error) {
+	events, err := listAll(metav1.ListOptions{FieldSelector: fieldSelector}, func(opts metav1.ListOptions) ([]corev1.Event, string, error) {
+		list, err := clientset.CoreV1().Events(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving events: %v", err)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return EventTime(&events[i]).After(EventTime(&events[j]))
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// EventTime returns when an event last occurred, falling back to the newer event
+// fields and the creation time for events that don't set lastTimestamp
+func EventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// GetNamespaceWarnings returns up to limit non-Normal events from anywhere in the namespace,
+// most recent first, to surface namespace-wide problems such as exceeded quotas or node pressure
+func GetNamespaceWarnings(clientset kubernetes.Interface, namespace string, limit int) ([]corev1.Event, error) {
+	return GetRecentEvents(clientset, namespace, "type!="+corev1.EventTypeNormal, limit)
+}
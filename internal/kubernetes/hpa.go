@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetHPAForDeployment returns the HorizontalPodAutoscaler targeting the given deployment,
+// or nil if the deployment isn't autoscaled
+func GetHPAForDeployment(clientset kubernetes.Interface, namespace, deploymentName string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas, err := listAll(metav1.ListOptions{}, func(opts metav1.ListOptions) ([]autoscalingv2.HorizontalPodAutoscaler, string, error) {
+		list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving horizontal pod autoscalers: %v", err)
+	}
+
+	for i, hpa := range hpas {
+		target := hpa.Spec.ScaleTargetRef
+		if target.Kind == "Deployment" && target.Name == deploymentName {
+			return &hpas[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// hpaSpecMetricName names a metric the HPA scales on, e.g. "cpu", "app/cpu" for a container resource
+// or "http_requests" for a pods, object or external metric
+func hpaSpecMetricName(spec autoscalingv2.MetricSpec) string {
+	switch {
+	case spec.Resource != nil:
+		return string(spec.Resource.Name)
+	case spec.ContainerResource != nil:
+		return spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name)
+	case spec.Pods != nil:
+		return spec.Pods.Metric.Name
+	case spec.Object != nil:
+		return spec.Object.Metric.Name
+	case spec.External != nil:
+		return spec.External.Metric.Name
+	}
+	return string(spec.Type)
+}
+
+// hpaStatusMetric names a metric the HPA reported, like hpaSpecMetricName, and returns its current value
+func hpaStatusMetric(status autoscalingv2.MetricStatus) (string, *autoscalingv2.MetricValueStatus) {
+	switch {
+	case status.Resource != nil:
+		return string(status.Resource.Name), &status.Resource.Current
+	case status.ContainerResource != nil:
+		return status.ContainerResource.Container + "/" + string(status.ContainerResource.Name), &status.ContainerResource.Current
+	case status.Pods != nil:
+		return status.Pods.Metric.Name, &status.Pods.Current
+	case status.Object != nil:
+		return status.Object.Metric.Name, &status.Object.Current
+	case status.External != nil:
+		return status.External.Metric.Name, &status.External.Current
+	}
+	return string(status.Type), nil
+}
+
+// HPAStatusReported reports whether the HPA controller has synced the HPA, i.e. filled in its status.
+// Until then, e.g. right after it was created, every metric looks unknown.
+func HPAStatusReported(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	return hpa.Status.ObservedGeneration != nil || len(hpa.Status.Conditions) > 0 || len(hpa.Status.CurrentMetrics) > 0
+}
+
+// GetHPAUnknownMetrics returns the names of the metrics the HPA scales on that have no current value in
+// its status, shown as <unknown> by kubectl: the metrics pipeline (e.g. metrics-server) isn't feeding it
+func GetHPAUnknownMetrics(hpa *autoscalingv2.HorizontalPodAutoscaler) []string {
+	known := make(map[string]bool)
+	for _, status := range hpa.Status.CurrentMetrics {
+		name, current := hpaStatusMetric(status)
+		if current != nil && (current.Value != nil || current.AverageValue != nil || current.AverageUtilization != nil) {
+			known[string(status.Type)+"/"+name] = true
+		}
+	}
+
+	var unknown []string
+	for _, spec := range hpa.Spec.Metrics {
+		name := hpaSpecMetricName(spec)
+		if !known[string(spec.Type)+"/"+name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// GetHPAScalingActive reads the HPA's ScalingActive condition: whether it is able to compute a replica count,
+// and otherwise the condition's reason and message, e.g. "FailedGetResourceMetric: unable to get metrics ...".
+// An HPA whose controller hasn't reported the condition yet counts as active.
+func GetHPAScalingActive(hpa *autoscalingv2.HorizontalPodAutoscaler) (bool, string) {
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type != autoscalingv2.ScalingActive || condition.Status != corev1.ConditionFalse {
+			continue
+		}
+		if condition.Message != "" {
+			return false, fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+		return false, condition.Reason
+	}
+	return true, ""
+}
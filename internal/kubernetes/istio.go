@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// virtualServiceVersions are the networking.istio.io versions serving VirtualServices, preferred first
+var virtualServiceVersions = []string{"v1", "v1beta1", "v1alpha3"}
+
+// VirtualServiceGVR returns the newest served version of the Istio VirtualService resource,
+// and false when the Istio CRDs aren't installed
+func VirtualServiceGVR(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, bool, error) {
+	for _, version := range virtualServiceVersions {
+		gvr := schema.GroupVersionResource{Group: "networking.istio.io", Version: version, Resource: "virtualservices"}
+		installed, err := resourceInstalled(discoveryClient, gvr)
+		if err != nil {
+			return schema.GroupVersionResource{}, false, err
+		}
+		if installed {
+			return gvr, true, nil
+		}
+	}
+	return schema.GroupVersionResource{}, false, nil
+}
+
+// GetVirtualServicesForService returns the VirtualServices ("namespace/name") with an HTTP, TLS or TCP route
+// to the service. They are listed cluster-wide, as they often live with the gateway, falling back to the
+// service's namespace when that is forbidden.
+func GetVirtualServicesForService(dynClient dynamic.Interface, gvr schema.GroupVersionResource, service *corev1.Service) ([]string, error) {
+	virtualServices, err := ListUnstructured(dynClient, gvr, metav1.NamespaceAll, "")
+	if apierrors.IsForbidden(err) {
+		virtualServices, err = ListUnstructured(dynClient, gvr, service.Namespace, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving virtual services: %v", err)
+	}
+
+	var names []string
+	for _, virtualService := range virtualServices {
+		if virtualServiceRoutesTo(virtualService, service) {
+			names = append(names, virtualService.GetNamespace()+"/"+virtualService.GetName())
+		}
+	}
+	return names, nil
+}
+
+// virtualServiceRoutesTo reports whether any route destination of the VirtualService is the service
+func virtualServiceRoutesTo(virtualService unstructured.Unstructured, service *corev1.Service) bool {
+	for _, routeType := range []string{"http", "tls", "tcp"} {
+		routes, _, _ := unstructured.NestedSlice(virtualService.Object, "spec", routeType)
+		for _, route := range routes {
+			routeMap, ok := route.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			destinations, _, _ := unstructured.NestedSlice(routeMap, "route")
+			for _, destination := range destinations {
+				destinationMap, ok := destination.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				host, _, _ := unstructured.NestedString(destinationMap, "destination", "host")
+				if hostIsService(host, virtualService.GetNamespace(), service) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hostIsService reports whether a destination host names the service: its short name, resolved in the
+// VirtualService's namespace, or its name.namespace[.svc[.<cluster domain>]] form
+func hostIsService(host, virtualServiceNamespace string, service *corev1.Service) bool {
+	if host == service.Name {
+		return virtualServiceNamespace == service.Namespace
+	}
+	qualified := service.Name + "." + service.Namespace
+	return host == qualified || host == qualified+".svc" || strings.HasPrefix(host, qualified+".svc.")
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetJobsByLabel returns the Jobs matching the given label selector
+func GetJobsByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]batchv1.Job, error) {
+	jobs, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]batchv1.Job, string, error) {
+		list, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving jobs: %v", err)
+	}
+	return jobs, nil
+}
+
+// GetCronJobsByLabel returns the CronJobs matching the given label selector
+func GetCronJobsByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]batchv1.CronJob, error) {
+	cronJobs, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]batchv1.CronJob, string, error) {
+		list, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving cronjobs: %v", err)
+	}
+	return cronJobs, nil
+}
+
+// GetBatchInfo describes the CronJobs and Jobs matching the label selector: schedule and last
+// runs for CronJobs, completions and failures for Jobs. It returns "" when there are none, or
+// they can't be listed (e.g. no RBAC access to batch resources), so the panel is only shown when useful.
+func GetBatchInfo(clientset kubernetes.Interface, namespace, labelSelector string) string {
+	var sb strings.Builder
+
+	cronJobs, _ := GetCronJobsByLabel(clientset, namespace, labelSelector)
+	for _, cronJob := range cronJobs {
+		suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+		sb.WriteString(fmt.Sprintf("CronJob: %s\n  Schedule: %s\n  Suspended: %t\n  Active: %d\n  Last Schedule: %s\n  Last Success: %s\n",
+			cronJob.Name,
+			cronJob.Spec.Schedule,
+			suspended,
+			len(cronJob.Status.Active),
+			formatJobTime(cronJob.Status.LastScheduleTime),
+			formatJobTime(cronJob.Status.LastSuccessfulTime)))
+	}
+
+	jobs, _ := GetJobsByLabel(clientset, namespace, labelSelector)
+	for _, job := range jobs {
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		sb.WriteString(fmt.Sprintf("Job: %s\n  Completions: %d/%d\n  Failed: %d\n  Active: %d\n  Started: %s\n  Completed: %s\n",
+			job.Name,
+			job.Status.Succeeded,
+			completions,
+			job.Status.Failed,
+			job.Status.Active,
+			formatJobTime(job.Status.StartTime),
+			formatJobTime(job.Status.CompletionTime)))
+	}
+
+	return sb.String()
+}
+
+// formatJobTime renders a job timestamp with its age, "never" when unset
+func formatJobTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), time.Since(t.Time).Round(time.Second))
+}
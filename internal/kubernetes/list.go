@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// listPageSize is the number of items requested per page by listAll
+const listPageSize = 500
+
+// listAll calls a List function page by page, following continue tokens until
+// the server has returned every item, and aggregates the results
+func listAll[T any](opts metav1.ListOptions, list func(metav1.ListOptions) ([]T, string, error)) ([]T, error) {
+	var items []T
+	opts.Limit = listPageSize
+
+	for {
+		page, next, err := list(opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+
+		if next == "" {
+			return items, nil
+		}
+		opts.Continue = next
+	}
+}
+
+// ListUnstructured returns every resource of the given type in the namespace matching the label selector
+func ListUnstructured(dynClient dynamic.Interface, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]unstructured.Unstructured, error) {
+	return listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]unstructured.Unstructured, string, error) {
+		list, err := dynClient.Resource(gvr).Namespace(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.GetContinue(), nil
+	})
+}
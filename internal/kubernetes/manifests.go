@@ -0,0 +1,182 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestSet holds the objects decoded from a directory of rendered manifests
+type ManifestSet struct {
+	// Typed are the objects of built-in kinds, decoded into their API types
+	Typed []runtime.Object
+	// Unstructured are the objects of kinds the client doesn't know, e.g. custom resources
+	Unstructured []*unstructured.Unstructured
+}
+
+// LoadManifests decodes every YAML or JSON document in the .yaml, .yml and .json files under dir
+func LoadManifests(dir string) (*ManifestSet, error) {
+	set := &ManifestSet{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if err := set.loadFile(path); err != nil {
+			return fmt.Errorf("error decoding manifest %s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// loadFile decodes each document of a (multi-document) manifest file
+func (set *ManifestSet) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(file))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := set.decode(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// decode adds a document as a typed object, falling back to unstructured for unknown kinds
+func (set *ManifestSet) decode(doc []byte) error {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+	if err == nil {
+		set.Typed = append(set.Typed, obj)
+		return nil
+	}
+	if !runtime.IsNotRegisteredError(err) {
+		return err
+	}
+
+	data, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	set.Unstructured = append(set.Unstructured, u)
+	return nil
+}
+
+// NewManifestClients returns typed and dynamic clients serving the manifests as if they had been
+// applied to the namespace, so the rules run unchanged against them. Objects without a namespace are
+// placed in it, and every Deployment gets one pod built from its template for the pod rules to check.
+// listKinds maps the custom resources listed by rules to their list kind.
+func NewManifestClients(set *ManifestSet, namespace string, listKinds map[schema.GroupVersionResource]string) (kubernetes.Interface, dynamic.Interface) {
+	var typed []runtime.Object
+	for _, obj := range set.Typed {
+		switch obj.(type) {
+		case *corev1.Namespace, *corev1.Node:
+			// Cluster-scoped, keep them out of the namespace
+		default:
+			if accessor, err := meta.Accessor(obj); err == nil && accessor.GetNamespace() == "" {
+				accessor.SetNamespace(namespace)
+			}
+		}
+		typed = append(typed, obj)
+
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			typed = append(typed, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        deployment.Name + "-manifest",
+					Namespace:   deployment.Namespace,
+					Labels:      deployment.Spec.Template.Labels,
+					Annotations: deployment.Spec.Template.Annotations,
+				},
+				Spec: deployment.Spec.Template.Spec,
+			})
+		}
+	}
+
+	kinds := make(map[schema.GroupVersionResource]string)
+	for gvr, kind := range listKinds {
+		kinds[gvr] = kind
+	}
+	clientset := fake.NewClientset(typed...)
+	// Advertise the custom resources found in the manifests, so discovery reports them as installed
+	fakeDiscovery, _ := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	var unstructuredObjs []runtime.Object
+	for _, u := range set.Unstructured {
+		if u.GetNamespace() == "" {
+			u.SetNamespace(namespace)
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(u.GroupVersionKind())
+		if _, exists := kinds[gvr]; !exists {
+			kinds[gvr] = u.GetKind() + "List"
+			if fakeDiscovery != nil {
+				advertiseResource(fakeDiscovery, gvr, u.GetKind())
+			}
+		}
+		unstructuredObjs = append(unstructuredObjs, u)
+	}
+
+	return clientset, dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), kinds, unstructuredObjs...)
+}
+
+// advertiseResource adds the resource to the fake discovery, grouping resources by group version
+func advertiseResource(fakeDiscovery *fakediscovery.FakeDiscovery, gvr schema.GroupVersionResource, kind string) {
+	resource := metav1.APIResource{Name: gvr.Resource, Kind: kind, Namespaced: true}
+	for _, list := range fakeDiscovery.Resources {
+		if list.GroupVersion == gvr.GroupVersion().String() {
+			list.APIResources = append(list.APIResources, resource)
+			return
+		}
+	}
+	fakeDiscovery.Resources = append(fakeDiscovery.Resources, &metav1.APIResourceList{
+		GroupVersion: gvr.GroupVersion().String(),
+		APIResources: []metav1.APIResource{resource},
+	})
+}
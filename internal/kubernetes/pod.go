@@ -3,17 +3,21 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/rivo/tview"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 // GetPodInfo fetches pod details from the Kubernetes cluster
-func GetPodInfo(clientset *kubernetes.Clientset, namespace, podName string) string {
+func GetPodInfo(clientset kubernetes.Interface, namespace, podName string) string {
 	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Sprintf("Error retrieving pod: %v", err)
@@ -39,53 +43,374 @@ func GetPodInfo(clientset *kubernetes.Clientset, namespace, podName string) stri
 }
 
 // GetPodInfoByLabel fetches pod details using a label selector
-func GetPodInfoByLabel(clientset *kubernetes.Clientset, namespace, labelSelector string) []string {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: labelSelector,
-	})
-
+func GetPodInfoByLabel(clientset kubernetes.Interface, namespace, labelSelector string) []string {
+	pods, err := GetPodsByLabel(clientset, namespace, labelSelector)
 	if err != nil {
 		return []string{fmt.Sprintf("Error retrieving pods: %v", err)}
 	}
 
-	if len(pods.Items) == 0 {
+	if len(pods) == 0 {
 		return []string{"No pods found with the specified label"}
 	}
 
-	results := make([]string, len(pods.Items))
+	results := make([]string, len(pods))
 
-	for i, pod := range pods.Items {
+	for i, pod := range pods {
+		// A pod stuck initializing stays Pending, its init status (e.g. Init:CrashLoopBackOff) tells why
+		status := string(pod.Status.Phase)
+		if initStatus, failed := podInitStatus(&pod); failed {
+			status += " [red](" + initStatus + ")[white]"
+		} else if initStatus != "" {
+			status += " (" + initStatus + ")"
+		}
 		results[i] = fmt.Sprintf("Name: %s\nNamespace: %s\nStatus: %s\nNode: %s\nIP: %s\n",
 			pod.Name,
 			pod.Namespace,
-			pod.Status.Phase,
+			status,
 			pod.Spec.NodeName,
 			pod.Status.PodIP)
+		if len(pod.Status.InitContainerStatuses) > 0 {
+			results[i] += "Init Containers:\n"
+			for _, initStatus := range pod.Status.InitContainerStatuses {
+				results[i] += fmt.Sprintf("  %s\n", formatInitContainerStatus(initStatus))
+			}
+		}
+		// Crashes (OOMKilled above all) don't show in the phase of a pod that restarted since
+		if terminations := GetPodTerminationReasons(&pod); len(terminations) > 0 {
+			results[i] += "Last Terminations:\n"
+			for _, termination := range terminations {
+				results[i] += fmt.Sprintf("  %s\n", termination)
+			}
+		}
 	}
 
 	return results
 }
 
-// GetPodNamesByLabel returns a slice of pod names that match the given label selector
-func GetPodNamesByLabel(clientset *kubernetes.Clientset, namespace, labelSelector string) []string {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: labelSelector,
+// GetPodsByLabel returns the pod objects matching the given label selector
+func GetPodsByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]corev1.Pod, error) {
+	pods, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]corev1.Pod, string, error) {
+		list, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pods: %v", err)
+	}
+	return pods, nil
+}
 
+// GetPodNamesByLabel returns a slice of pod names that match the given label selector
+func GetPodNamesByLabel(clientset kubernetes.Interface, namespace, labelSelector string) []string {
+	pods, err := GetPodsByLabel(clientset, namespace, labelSelector)
 	if err != nil {
 		return []string{}
 	}
 
 	var podNames []string
-	for _, pod := range pods.Items {
+	for _, pod := range pods {
 		podNames = append(podNames, pod.Name)
 	}
 
 	return podNames
 }
 
+// IsPodReady reports whether the pod's Ready condition is true. Terminating pods are not counted as ready.
+func IsPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// initContainerWaitingFailures are the waiting reasons of an init container that won't get past it by itself
+var initContainerWaitingFailures = []string{
+	"CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError",
+}
+
+// initContainerFailed reports whether the init container failed or is stuck failing
+func initContainerFailed(status corev1.ContainerStatus) bool {
+	if terminated := status.State.Terminated; terminated != nil {
+		return terminated.ExitCode != 0
+	}
+	if waiting := status.State.Waiting; waiting != nil {
+		return slices.Contains(initContainerWaitingFailures, waiting.Reason)
+	}
+	return false
+}
+
+// podInitStatus returns the status of the pod's initialization as kubectl shows it, e.g. "Init:1/2",
+// "Init:Error" or "Init:CrashLoopBackOff", and whether an init container failed. It is "" once every
+// init container completed (native sidecars once they started).
+func podInitStatus(pod *corev1.Pod) (string, bool) {
+	done := 0
+	for _, status := range pod.Status.InitContainerStatuses {
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+			done++
+		case status.State.Running != nil && status.Started != nil && *status.Started && isRestartableInitContainer(pod, status.Name):
+			done++
+		case initContainerFailed(status) && status.State.Terminated != nil:
+			reason := status.State.Terminated.Reason
+			if reason == "" {
+				reason = fmt.Sprintf("ExitCode:%d", status.State.Terminated.ExitCode)
+			}
+			return "Init:" + reason, true
+		case initContainerFailed(status):
+			return "Init:" + status.State.Waiting.Reason, true
+		}
+	}
+	if done == len(pod.Status.InitContainerStatuses) {
+		return "", false
+	}
+	return fmt.Sprintf("Init:%d/%d", done, len(pod.Status.InitContainerStatuses)), false
+}
+
+// isRestartableInitContainer reports whether the named init container is a native sidecar, running
+// alongside the app containers once started
+func isRestartableInitContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == name {
+			return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		}
+	}
+	return false
+}
+
+// formatInitContainerStatus describes the state of an init container, e.g. "migrate: Completed" or, in red,
+// "migrate: CrashLoopBackOff (exit code 1, 5 restarts)"
+func formatInitContainerStatus(status corev1.ContainerStatus) string {
+	state := "Waiting"
+	exitCode := int32(-1)
+	switch {
+	case status.State.Terminated != nil:
+		state = status.State.Terminated.Reason
+		if state == "" {
+			state = "Terminated"
+		}
+		exitCode = status.State.Terminated.ExitCode
+	case status.State.Running != nil:
+		state = "Running"
+	case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+		state = status.State.Waiting.Reason
+		// A crash-looping container is waiting between restarts, its last run tells how it failed
+		if last := status.LastTerminationState.Terminated; last != nil {
+			exitCode = last.ExitCode
+		}
+	}
+
+	var details []string
+	if exitCode > 0 {
+		details = append(details, fmt.Sprintf("exit code %d", exitCode))
+	}
+	if status.RestartCount > 0 {
+		details = append(details, fmt.Sprintf("%d restarts", status.RestartCount))
+	}
+	text := fmt.Sprintf("%s: %s", status.Name, state)
+	if len(details) > 0 {
+		text += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+	if initContainerFailed(status) {
+		return "[red]" + tview.Escape(text) + "[white]"
+	}
+	return tview.Escape(text)
+}
+
+// ContainerTermination is the last termination of a container, e.g. an OOMKilled one
+type ContainerTermination struct {
+	Container string
+	Reason    string
+	ExitCode  int32
+	Restarts  int32
+}
+
+// String describes the termination, e.g. "app: OOMKilled (exit code 137, 4 restarts)"
+func (t ContainerTermination) String() string {
+	return fmt.Sprintf("%s: %s (exit code %d, %d restarts)", t.Container, t.Reason, t.ExitCode, t.Restarts)
+}
+
+// OOMKilled reports whether the container was killed for exceeding its memory limit
+func (t ContainerTermination) OOMKilled() bool {
+	return t.Reason == "OOMKilled"
+}
+
+// GetPodTerminationReasons returns the last termination of each container of the pod (init containers
+// included) that was terminated: its current state when it is terminated, or else its lastState
+func GetPodTerminationReasons(pod *corev1.Pod) []ContainerTermination {
+	var terminations []ContainerTermination
+	statuses := append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+		// A completed init container terminated as intended
+		if terminated == nil || (terminated.Reason == "Completed" && terminated.ExitCode == 0) {
+			continue
+		}
+		reason := terminated.Reason
+		if reason == "" {
+			reason = "Error"
+		}
+		terminations = append(terminations, ContainerTermination{
+			Container: status.Name,
+			Reason:    reason,
+			ExitCode:  terminated.ExitCode,
+			Restarts:  status.RestartCount,
+		})
+	}
+	return terminations
+}
+
+// PodResourceTotals holds the CPU and memory requests and limits summed across pods
+type PodResourceTotals struct {
+	// Pods is the number of running pods summed
+	Pods     int
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// SumPodResources sums the CPU and memory requests and limits of the running pods' containers,
+// native sidecars included. Regular init containers don't run alongside the app and are left out.
+func SumPodResources(pods []corev1.Pod) PodResourceTotals {
+	totals := PodResourceTotals{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		totals.Pods++
+		containers := slices.Clone(pod.Spec.Containers)
+		for _, container := range pod.Spec.InitContainers {
+			if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+				containers = append(containers, container)
+			}
+		}
+		for _, container := range containers {
+			addResources(totals.Requests, container.Resources.Requests)
+			addResources(totals.Limits, container.Resources.Limits)
+		}
+	}
+	return totals
+}
+
+// addResources adds the CPU and memory of resources to total
+func addResources(total, resources corev1.ResourceList) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if quantity, exists := resources[name]; exists {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+}
+
+// podMetricsList is the part of a metrics.k8s.io PodMetricsList holding the container usage
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// GetPodsUsage sums the current CPU and memory usage of the pods reported by the metrics API
+// (metrics-server). It returns nil without an error when the metrics API isn't served.
+func GetPodsUsage(clientset kubernetes.Interface, namespace string, pods []corev1.Pod) (corev1.ResourceList, error) {
+	if _, err := CachedServerResourcesForGroupVersion(clientset.Discovery(), "metrics.k8s.io/v1beta1"); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error discovering metrics.k8s.io/v1beta1: %v", err)
+	}
+
+	raw, err := clientset.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").
+		DoRaw(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pod metrics: %v", err)
+	}
+	var metrics podMetricsList
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, fmt.Errorf("error decoding pod metrics: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, pod := range pods {
+		names[pod.Name] = true
+	}
+	usage := corev1.ResourceList{}
+	for _, item := range metrics.Items {
+		if !names[item.Metadata.Name] {
+			continue
+		}
+		for _, container := range item.Containers {
+			addResources(usage, container.Usage)
+		}
+	}
+	return usage, nil
+}
+
+// FormatPodResourceTotals renders the summed requests, limits and (when not nil) usage side by side,
+// CPU in millicores and memory in MiB
+func FormatPodResourceTotals(totals PodResourceTotals, usage corev1.ResourceList) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Resource totals (%d running pods):\n", totals.Pods))
+	header := fmt.Sprintf("  %-8s %10s %10s", "", "Requests", "Limits")
+	if usage != nil {
+		header += fmt.Sprintf(" %10s", "Usage")
+	}
+	sb.WriteString(header + "\n")
+
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		format := formatMilliCPU
+		label := "CPU"
+		if name == corev1.ResourceMemory {
+			format, label = formatMebibytes, "Memory"
+		}
+		line := fmt.Sprintf("  %-8s %10s %10s", label, format(totals.Requests, name), format(totals.Limits, name))
+		if usage != nil {
+			line += fmt.Sprintf(" %10s", format(usage, name))
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// formatMilliCPU renders a CPU quantity in millicores, "-" when unset
+func formatMilliCPU(resources corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, exists := resources[name]
+	if !exists {
+		return "-"
+	}
+	return fmt.Sprintf("%dm", quantity.MilliValue())
+}
+
+// formatMebibytes renders a memory quantity in MiB, "-" when unset
+func formatMebibytes(resources corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, exists := resources[name]
+	if !exists {
+		return "-"
+	}
+	return fmt.Sprintf("%dMi", quantity.Value()/(1024*1024))
+}
+
+// IsSidecarContainer reports whether a container name belongs to a known service mesh sidecar
+func IsSidecarContainer(name string) bool {
+	return name == "istio-proxy" || name == "envoy" || name == "linkerd"
+}
+
 // GetPodContainers retrieves the list of container names in a pod
-func GetPodContainers(clientset *kubernetes.Clientset, namespace, podName string) ([]string, error) {
+func GetPodContainers(clientset kubernetes.Interface, namespace, podName string) ([]string, error) {
 	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving pod: %v", err)
@@ -99,7 +424,7 @@ func GetPodContainers(clientset *kubernetes.Clientset, namespace, podName string
 	// Categorize regular containers
 	for _, container := range pod.Spec.Containers {
 		name := container.Name
-		if name == "istio-proxy" || name == "envoy" || name == "linkerd" {
+		if IsSidecarContainer(name) {
 			sidecarContainers = append(sidecarContainers, name)
 		} else {
 			appContainers = append(appContainers, name)
@@ -119,7 +444,7 @@ func GetPodContainers(clientset *kubernetes.Clientset, namespace, podName string
 }
 
 // GetPodLogs retrieves logs from a pod's container
-func GetPodLogs(clientset *kubernetes.Clientset, namespace, podName string, tailLines int64, containerName string) (string, error) {
+func GetPodLogs(clientset kubernetes.Interface, namespace, podName string, tailLines int64, containerName string) (string, error) {
 	// If no container specified, get container names and try to find the most appropriate one
 	if containerName == "" {
 		containers, err := GetPodContainers(clientset, namespace, podName)
@@ -157,7 +482,7 @@ func GetPodLogs(clientset *kubernetes.Clientset, namespace, podName string, tail
 }
 
 // RenderPod renders the pod details in the TUI for pods matching the label selector
-func RenderPod(clientset *kubernetes.Clientset, app *tview.Application, namespace string, labelSelector string) {
+func RenderPod(clientset kubernetes.Interface, app *tview.Application, namespace string, labelSelector string) {
 	podInfoList := GetPodInfoByLabel(clientset, namespace, labelSelector)
 
 	// Create a new flex layout for pod information
@@ -173,6 +498,7 @@ func RenderPod(clientset *kubernetes.Clientset, app *tview.Application, namespac
 		// Display information for each pod
 		for i, podInfo := range podInfoList {
 			podTextView := tview.NewTextView()
+			podTextView.SetDynamicColors(true)
 			podTextView.SetBorder(true)
 			podTextView.SetTitle(fmt.Sprintf("Pod %d", i+1))
 			podTextView.SetText(podInfo)
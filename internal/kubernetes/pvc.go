@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodClaimNames returns the names of the PersistentVolumeClaims mounted by the pods, in order of first use
+func PodClaimNames(pods []corev1.Pod) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, pod := range pods {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || seen[volume.PersistentVolumeClaim.ClaimName] {
+				continue
+			}
+			seen[volume.PersistentVolumeClaim.ClaimName] = true
+			names = append(names, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
+
+// GetPVCsForPods fetches the PersistentVolumeClaims mounted by the pods, returning separately
+// the names of claims that don't exist
+func GetPVCsForPods(clientset kubernetes.Interface, namespace string, pods []corev1.Pod) ([]corev1.PersistentVolumeClaim, []string, error) {
+	var claims []corev1.PersistentVolumeClaim
+	var missing []string
+	for _, name := range PodClaimNames(pods) {
+		claim, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return claims, missing, fmt.Errorf("error retrieving persistent volume claim %s: %v", name, err)
+		}
+		claims = append(claims, *claim)
+	}
+	return claims, missing, nil
+}
+
+// GetPVCInfo describes the status, storage class and capacity of the PersistentVolumeClaims used
+// by the pods matching the label selector. It returns "" when the pods use no claims.
+func GetPVCInfo(clientset kubernetes.Interface, namespace, labelSelector string) string {
+	pods, err := GetPodsByLabel(clientset, namespace, labelSelector)
+	if err != nil || len(PodClaimNames(pods)) == 0 {
+		return ""
+	}
+
+	claims, missing, err := GetPVCsForPods(clientset, namespace, pods)
+	var sb strings.Builder
+	for _, claim := range claims {
+		storageClass := "<default>"
+		if claim.Spec.StorageClassName != nil {
+			storageClass = *claim.Spec.StorageClassName
+		}
+		capacity := "-"
+		if size, exists := claim.Status.Capacity[corev1.ResourceStorage]; exists {
+			capacity = size.String()
+		}
+		sb.WriteString(fmt.Sprintf("PVC: %s\n  Status: %s\n  Storage Class: %s\n  Capacity: %s\n  Volume: %s\n",
+			claim.Name, claim.Status.Phase, storageClass, capacity, claim.Spec.VolumeName))
+	}
+	for _, name := range missing {
+		sb.WriteString(fmt.Sprintf("PVC: %s\n  Status: NOT FOUND\n", name))
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("%v\n", err))
+	}
+	return sb.String()
+}
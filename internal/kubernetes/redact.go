@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// ipv4Pattern matches IPv4 addresses
+var ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// Redactor masks cluster-identifying details (node names, IPv4 addresses, the API server URL) in
+// rendered output with placeholders such as <node-1>, <ip-2> or <server>. The same value always maps to the
+// same placeholder, so redacted reports stay readable. A nil Redactor leaves text unchanged.
+// It is safe for concurrent use.
+type Redactor struct {
+	mu           sync.Mutex
+	names        map[string]string // literal values to replace, and their kind
+	namePattern  *regexp.Regexp
+	placeholders map[string]string
+	counts       map[string]int
+}
+
+// NewRedactor returns a Redactor for the cluster's node names, including the nodes the pods of the
+// namespace run on when nodes can't be listed, and the API server URL and host
+func NewRedactor(clientset kubernetes.Interface, namespace, serverURL string) *Redactor {
+	r := &Redactor{
+		names:        make(map[string]string),
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+
+	if nodes, err := GetNodes(clientset); err == nil {
+		for _, node := range nodes {
+			r.names[node.Name] = "node"
+		}
+	}
+	if pods, err := GetPodsByLabel(clientset, namespace, ""); err == nil {
+		for _, pod := range pods {
+			if pod.Spec.NodeName != "" {
+				r.names[pod.Spec.NodeName] = "node"
+			}
+		}
+	}
+	// The server URL and its host both read <server>
+	if serverURL != "" {
+		r.names[serverURL] = "server"
+		r.placeholders[serverURL] = "<server>"
+		if parsed, err := url.Parse(serverURL); err == nil && parsed.Hostname() != "" {
+			r.names[parsed.Hostname()] = "server"
+			r.placeholders[parsed.Hostname()] = "<server>"
+		}
+	}
+	r.compile()
+	return r
+}
+
+// compile builds the pattern matching the literal values, longest first so a node named after
+// its IP address is replaced as a whole
+func (r *Redactor) compile() {
+	if len(r.names) == 0 {
+		return
+	}
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	// Only replace whole names, not a node name that happens to be part of a longer word
+	r.namePattern = regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// placeholder returns the stable placeholder of a value, allocating the next one of its kind
+func (r *Redactor) placeholder(kind, value string) string {
+	if placeholder, exists := r.placeholders[value]; exists {
+		return placeholder
+	}
+	r.counts[kind]++
+	placeholder := fmt.Sprintf("<%s-%d>", kind, r.counts[kind])
+	r.placeholders[value] = placeholder
+	return placeholder
+}
+
+// Redact replaces the node names, IPv4 addresses and API server URL in the text
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.namePattern != nil {
+		text = r.namePattern.ReplaceAllStringFunc(text, func(name string) string {
+			return r.placeholder(r.names[name], name)
+		})
+	}
+	return ipv4Pattern.ReplaceAllStringFunc(text, func(ip string) string {
+		return r.placeholder("ip", ip)
+	})
+}
@@ -4,29 +4,22 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/rivo/tview"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 )
 
 // GetServiceInfo fetches service details from the Kubernetes cluster
-func GetServiceInfo(clientset *kubernetes.Clientset, namespace, serviceName string) string {
+// with the Istio port naming check marked by the given symbols
+func GetServiceInfo(clientset kubernetes.Interface, namespace, serviceName string, symbols StatusSymbols) string {
 	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Sprintf("Error retrieving service: %v", err)
-	}
-
-	portInfo := ""
-	for _, port := range service.Spec.Ports {
-		// Check if port follows Istio naming conventions
-		portNameValid := isValidIstioPortName(port.Name)
-		validation := "✓"
-		if !portNameValid {
-			validation = "✗"
-		}
-
-		portInfo += fmt.Sprintf("- Name: %s [%s], Port: %d, Target Port: %v, Protocol: %s\n",
-			port.Name, validation, port.Port, port.TargetPort.String(), port.Protocol)
+		return tview.Escape(fmt.Sprintf("Error retrieving service: %v", err))
 	}
 
 	// Add scrape_tls label info
@@ -35,18 +28,126 @@ func GetServiceInfo(clientset *kubernetes.Clientset, namespace, serviceName stri
 		scrapeTLS = "true"
 	}
 
-	info := fmt.Sprintf("Name: %s\nNamespace: %s\nClusterIP: %s\nType: %s\nSelector: %v\nscrape_tls: %s\nPorts:\n%s",
-		service.Name,
+	info := fmt.Sprintf("Name: %s\nNamespace: %s\nClusterIP: %s\nType: %s\nSelector: %s\nscrape_tls: %s\nPorts:\n%s",
+		tview.Escape(service.Name),
 		service.Namespace,
 		service.Spec.ClusterIP,
 		service.Spec.Type,
-		service.Spec.Selector,
+		tview.Escape(fmt.Sprintf("%v", service.Spec.Selector)),
 		scrapeTLS,
-		portInfo)
+		formatPortTable(service.Spec.Ports, symbols))
 
 	return info
 }
 
+// formatPortTable renders the service ports as a table aligned on fixed-width columns,
+// coloring the Istio port naming marker, left out with RawSymbols. Cells are padded before
+// color tags are added so the tags don't count towards the column widths.
+func formatPortTable(ports []corev1.ServicePort, symbols StatusSymbols) string {
+	header := []string{"Name", "Valid", "Port", "TargetPort", "Protocol", "AppProtocol"}
+	rows := [][]string{header}
+	valid := []bool{true}
+	for _, port := range ports {
+		// Check if port follows Istio naming conventions
+		portNameValid := isValidIstioPortName(port.Name)
+		valid = append(valid, portNameValid)
+		validation := symbols.symbol(portNameValid)
+		appProtocol := "-"
+		if port.AppProtocol != nil {
+			appProtocol = *port.AppProtocol
+		}
+		rows = append(rows, []string{
+			port.Name,
+			validation,
+			fmt.Sprintf("%d", port.Port),
+			port.TargetPort.String(),
+			string(port.Protocol),
+			appProtocol,
+		})
+	}
+
+	if symbols.raw() {
+		for r, row := range rows {
+			rows[r] = append(row[:1:1], row[2:]...)
+		}
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var sb strings.Builder
+	for r, row := range rows {
+		for i, cell := range row {
+			padded := cell
+			if i < len(row)-1 {
+				padded += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			}
+			switch {
+			case r == 0:
+				padded = "[yellow]" + padded + "[white]"
+			case symbols.raw():
+				padded = tview.Escape(padded)
+			case i == 1 && valid[r]:
+				padded = "[green]" + tview.Escape(padded) + "[white]"
+			case i == 1:
+				padded = "[red]" + tview.Escape(padded) + "[white]"
+			default:
+				padded = tview.Escape(padded)
+			}
+			sb.WriteString(padded)
+			if i < len(row)-1 {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// GetService fetches a service object by name
+func GetService(clientset kubernetes.Interface, namespace, serviceName string) (*corev1.Service, error) {
+	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving service: %v", err)
+	}
+	return service, nil
+}
+
+// GetServicesByLabel returns the services matching the given label selector
+func GetServicesByLabel(clientset kubernetes.Interface, namespace, labelSelector string) ([]corev1.Service, error) {
+	services, err := listAll(metav1.ListOptions{LabelSelector: labelSelector}, func(opts metav1.ListOptions) ([]corev1.Service, string, error) {
+		list, err := clientset.CoreV1().Services(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving services: %v", err)
+	}
+	return services, nil
+}
+
+// GetServiceEndpointSlices returns the EndpointSlices backing the given service
+func GetServiceEndpointSlices(clientset kubernetes.Interface, namespace, serviceName string) ([]discoveryv1.EndpointSlice, error) {
+	opts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, serviceName)}
+	slices, err := listAll(opts, func(opts metav1.ListOptions) ([]discoveryv1.EndpointSlice, string, error) {
+		list, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving endpoint slices: %v", err)
+	}
+	return slices, nil
+}
+
 // isValidIstioPortName checks if a port name follows Istio naming conventions
 func isValidIstioPortName(portName string) bool {
 	if portName == "" {
@@ -66,3 +167,105 @@ func isValidIstioPortName(portName string) bool {
 
 	return false
 }
+
+// istioPortProtocol returns the protocol a port name declares with its <protocol>[-<suffix>] prefix,
+// or "" when it doesn't follow the Istio naming conventions
+func istioPortProtocol(portName string) string {
+	if !isValidIstioPortName(strings.ToLower(portName)) {
+		return ""
+	}
+	protocol, _, _ := strings.Cut(strings.ToLower(portName), "-")
+	return protocol
+}
+
+// appProtocolName normalizes an appProtocol to the Istio protocol names, mapping the
+// kubernetes.io/ prefixed standard values (h2c, ws, wss) to their Istio equivalents
+func appProtocolName(appProtocol string) string {
+	switch protocol := strings.ToLower(appProtocol); protocol {
+	case "kubernetes.io/h2c":
+		return "http2"
+	case "kubernetes.io/ws":
+		return "http"
+	case "kubernetes.io/wss":
+		return "https"
+	default:
+		return protocol
+	}
+}
+
+// compatibleProtocols reports whether two declared protocols can describe the same port:
+// gRPC runs over HTTP/2 and HTTPS is TLS
+func compatibleProtocols(a, b string) bool {
+	if a == b {
+		return true
+	}
+	pair := map[string]bool{a: true, b: true}
+	return (pair["http2"] && (pair["grpc"] || pair["grpc-web"])) || (pair["https"] && pair["tls"])
+}
+
+// targetContainerPort returns the app container port a service port targets, by name or number (nil if none)
+func targetContainerPort(port corev1.ServicePort, pods []corev1.Pod) *corev1.ContainerPort {
+	// An unset targetPort defaults to the port itself
+	target := port.TargetPort
+	if target.Type == intstr.Int && target.IntVal == 0 {
+		target = intstr.FromInt32(port.Port)
+	}
+
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if IsSidecarContainer(container.Name) {
+				continue
+			}
+			for i, containerPort := range container.Ports {
+				if (target.Type == intstr.String && target.StrVal == containerPort.Name) ||
+					(target.Type == intstr.Int && target.IntVal == containerPort.ContainerPort) {
+					return &container.Ports[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PortProtocolConflicts returns the service ports whose protocol hints contradict each other, e.g.
+// "http-web: name says http, appProtocol says grpc". The protocol a port declares (its appProtocol, or else
+// its name prefix) is checked against the name prefix, the transport protocol (Istio protocols all run over
+// TCP) and, where the targeted container port of the pods has an Istio-style name, that name's prefix.
+func PortProtocolConflicts(service *corev1.Service, pods []corev1.Pod) []string {
+	if service == nil {
+		return nil
+	}
+
+	var conflicts []string
+	for _, port := range service.Spec.Ports {
+		nameProtocol := istioPortProtocol(port.Name)
+		declared := nameProtocol
+		var problems []string
+		if port.AppProtocol != nil && *port.AppProtocol != "" {
+			declared = appProtocolName(*port.AppProtocol)
+			if nameProtocol != "" && !compatibleProtocols(nameProtocol, declared) {
+				problems = append(problems, fmt.Sprintf("name says %s, appProtocol says %s", nameProtocol, *port.AppProtocol))
+			}
+		}
+		if declared == "" {
+			continue
+		}
+		if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			problems = append(problems, fmt.Sprintf("declared %s but protocol is %s", declared, port.Protocol))
+		}
+		if containerPort := targetContainerPort(port, pods); containerPort != nil {
+			if served := istioPortProtocol(containerPort.Name); served != "" && !compatibleProtocols(declared, served) {
+				problems = append(problems, fmt.Sprintf("declared %s but container port %s serves %s",
+					declared, containerPort.Name, served))
+			}
+		}
+		if len(problems) > 0 {
+			name := port.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", port.Port)
+			}
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", name, strings.Join(problems, ", ")))
+		}
+	}
+	return conflicts
+}
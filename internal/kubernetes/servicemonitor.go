@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// serviceMonitorGVR is the Prometheus Operator ServiceMonitor resource
+var serviceMonitorGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+
+// serviceMonitorSpec is the part of a ServiceMonitor spec that decides which Services it scrapes
+type serviceMonitorSpec struct {
+	Selector          metav1.LabelSelector `json:"selector"`
+	NamespaceSelector struct {
+		Any        bool     `json:"any"`
+		MatchNames []string `json:"matchNames"`
+	} `json:"namespaceSelector"`
+}
+
+// ServiceMonitorsInstalled reports whether the cluster serves the Prometheus Operator ServiceMonitor CRD
+func ServiceMonitorsInstalled(discoveryClient discovery.DiscoveryInterface) (bool, error) {
+	return resourceInstalled(discoveryClient, serviceMonitorGVR)
+}
+
+// resourceInstalled reports whether the cluster serves the resource, e.g. whether its CRD is installed
+func resourceInstalled(discoveryClient discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := CachedServerResourcesForGroupVersion(discoveryClient, gvr.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error discovering %s: %v", gvr.GroupVersion(), err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetServiceMonitorsForService returns the ServiceMonitors ("namespace/name") selecting the service.
+// They are listed cluster-wide, as they often live in a monitoring namespace, falling back to the
+// service's namespace when that is forbidden.
+func GetServiceMonitorsForService(dynClient dynamic.Interface, service *corev1.Service) ([]string, error) {
+	monitors, err := ListUnstructured(dynClient, serviceMonitorGVR, metav1.NamespaceAll, "")
+	if apierrors.IsForbidden(err) {
+		monitors, err = ListUnstructured(dynClient, serviceMonitorGVR, service.Namespace, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving service monitors: %v", err)
+	}
+
+	var names []string
+	for _, monitor := range monitors {
+		if serviceMonitorSelects(monitor, service) {
+			names = append(names, monitor.GetNamespace()+"/"+monitor.GetName())
+		}
+	}
+	return names, nil
+}
+
+// serviceMonitorSelects reports whether the ServiceMonitor's namespaceSelector and selector match
+// the service. Without a namespaceSelector only the ServiceMonitor's own namespace is watched.
+func serviceMonitorSelects(monitor unstructured.Unstructured, service *corev1.Service) bool {
+	specObj, found, err := unstructured.NestedMap(monitor.Object, "spec")
+	if err != nil || !found {
+		return false
+	}
+	var spec serviceMonitorSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(specObj, &spec); err != nil {
+		return false
+	}
+
+	switch {
+	case spec.NamespaceSelector.Any:
+	case len(spec.NamespaceSelector.MatchNames) > 0:
+		if !slices.Contains(spec.NamespaceSelector.MatchNames, service.Namespace) {
+			return false
+		}
+	default:
+		if monitor.GetNamespace() != service.Namespace {
+			return false
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&spec.Selector)
+	return err == nil && selector.Matches(labels.Set(service.Labels))
+}
@@ -0,0 +1,30 @@
+package kubernetes
+
+// StatusSymbols are the markers shown for passing and failing checks
+type StatusSymbols struct {
+	Success string
+	// Failure marks failing checks, and critical ones when Warning and Info are set
+	Failure string
+	// Warning and Info mark failing checks of these severities; empty falls back to Failure
+	Warning string
+	Info    string
+}
+
+// CheckMarkSymbols are the default markers of the deployment and service details
+var CheckMarkSymbols = StatusSymbols{Success: "✓", Failure: "✗"}
+
+// symbol returns the success or failure marker for a check
+func (s StatusSymbols) symbol(passed bool) string {
+	if passed {
+		return s.Success
+	}
+	return s.Failure
+}
+
+// RawSymbols render the deployment and service details as plain facts, without validation markers
+var RawSymbols = StatusSymbols{}
+
+// raw reports whether the symbols ask for the plain rendering without markers
+func (s StatusSymbols) raw() bool {
+	return s == RawSymbols
+}
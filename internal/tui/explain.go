@@ -0,0 +1,323 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleDoc documents what a rule checks, why it matters and how to fix a failure
+type RuleDoc struct {
+	Name        string
+	Checks      string
+	Why         string
+	Remediation string
+}
+
+// ruleDocs lists the documentation of every built-in rule, in evaluation order
+var ruleDocs = []RuleDoc{
+	{
+		Name:        "Service Account",
+		Checks:      "The pod's serviceAccountName is set and equals the value of its app label.",
+		Why:         "Istio derives the workload identity used for mTLS from the ServiceAccount. Pods sharing a generic account cannot be told apart by authorization policies.",
+		Remediation: "Create a ServiceAccount named after the app and set spec.template.spec.serviceAccountName on the Deployment.",
+	},
+	{
+		Name:        "Default Service Account",
+		Checks:      "The pod runs as a ServiceAccount other than the namespace's default one (an unset serviceAccountName means default).",
+		Why:         "Every workload without its own account shares default, so they all get the same identity and any permissions granted to it. It usually means no dedicated ServiceAccount was created at all.",
+		Remediation: "Create a ServiceAccount for the app and set spec.template.spec.serviceAccountName on the Deployment.",
+	},
+	{
+		Name:        "Dropped Capabilities",
+		Checks:      "Every app container (sidecars excluded) sets securityContext.capabilities.drop: [ALL]. Capabilities added back are listed so they can be reviewed.",
+		Why:         "The default capability set (NET_RAW, CHOWN, SETUID...) is more than a typical app needs and widens what an attacker can do after a container escape or RCE.",
+		Remediation: "Set securityContext.capabilities.drop: [\"ALL\"] on each container and add back only the specific capabilities it needs, e.g. add: [\"NET_BIND_SERVICE\"].",
+	},
+	{
+		Name:        "Health Probes",
+		Checks:      "In at least one pod, every app container (service mesh sidecars excluded) defines both a livenessProbe and a readinessProbe. The containers and the probes they miss are listed.",
+		Why:         "Without a readiness probe a pod gets traffic as soon as it starts, and a rolling update replaces old pods before the new ones can serve; without a liveness probe a hung container is never restarted.",
+		Remediation: "Add a readinessProbe checking the app can serve requests and a livenessProbe checking it isn't hung (a cheaper check, e.g. /healthz) to each app container.",
+	},
+	{
+		Name:        "Probe Timings",
+		Checks:      fmt.Sprintf("Every app container with a liveness probe either has a startupProbe or waits at least %d seconds (initialDelaySeconds) before the first check.", minLivenessInitialDelaySeconds),
+		Why:         "A liveness probe that fires before a slow-starting app (JVM, large caches) is up kills the container during boot and leaves it in CrashLoopBackOff.",
+		Remediation: "Add a startupProbe to slow-starting containers, or raise livenessProbe.initialDelaySeconds above the app's startup time.",
+	},
+	{
+		Name:        "Distinct Liveness Probe",
+		Checks:      fmt.Sprintf("Opt-in. No app container uses the exact same probe for liveness and readiness with a failureThreshold of %d or less.", aggressiveLivenessFailureThreshold),
+		Why:         "Readiness failures are expected under load or while a slow app warms up. When liveness is the same probe, every readiness blip also restarts the container, turning a slow start into a restart loop.",
+		Remediation: "Give the liveness probe a cheaper check (e.g. a /healthz that doesn't touch dependencies) or a higher failureThreshold/periodSeconds than the readiness probe.",
+	},
+	{
+		Name:        "Startup Probe",
+		Checks:      "Opt-in. Every app container that has a liveness probe also defines a startupProbe.",
+		Why:         "The liveness probe starts checking as soon as the container starts. JVM and legacy apps that take long to boot get killed before they are up, and end up in CrashLoopBackOff.",
+		Remediation: "Add a startupProbe (usually the same check as the liveness probe) with failureThreshold * periodSeconds covering the worst-case startup time.",
+	},
+	{
+		Name:        "Probe Ports",
+		Checks:      "Opt-in: every httpGet readiness, liveness and startup probe targets a port the pod declares; named ports must be declared by the probed container.",
+		Why:         "A probe pointing at a renamed or removed port always fails. For readiness probes that takes every pod out of the Service at once.",
+		Remediation: "Point the probe at an existing containerPort (by number or name), or add the missing port to the container's ports list.",
+	},
+	{
+		Name:        "Resource Limits",
+		Checks:      "Every app container (service mesh sidecars excluded) sets resources.requests and resources.limits for both CPU and memory. The containers and what they miss are listed.",
+		Why:         "Without requests the scheduler places the pod blind and it is the first evicted under node pressure; without limits one leaking or spinning container can starve every other pod on the node. Namespace quotas also reject pods that don't set them.",
+		Remediation: "Set resources.requests and resources.limits for cpu and memory on each app container, sized from its observed usage (e.g. kubectl top pods).",
+	},
+	{
+		Name:        "Resource Ratio",
+		Checks:      "Opt-in: no app container sets a CPU or memory limit more than -max-limit-ratio (default 10) times its request. Containers whose CPU limit equals the request are listed too, as they can never burst.",
+		Why:         "The scheduler packs pods by their requests. A limit far above the request lets one container take resources its neighbours were promised, while a CPU limit equal to the request throttles the app during startup and traffic spikes.",
+		Remediation: "Raise the request towards the usual usage and lower the limit towards the expected peak; leave some CPU headroom above the request for apps that burst.",
+	},
+	{
+		Name:        "Image Tag Policy",
+		Checks:      "Every container image (init containers included) is referenced by digest (@sha256:...), which always passes, or has a tag that is not latest nor a branch or channel name like main, develop or stable. Untagged images count as latest. The offending images are listed.",
+		Why:         "A mutable tag points at a different image after every push: pods started at different times run different code, a rollback redeploys the new image, and nothing records which build is actually running.",
+		Remediation: "Tag images with an immutable version or commit SHA (e.g. app:1.4.2 or app:3f9c2ab) and reference that tag, or pin the image by digest.",
+	},
+	{
+		Name:        "Image Pull Secrets",
+		Checks:      "Opt-in: pods whose images come from a registry other than the public ones (-public-registries, by default Docker Hub, registry.k8s.io, public.ecr.aws, mcr.microsoft.com and well-known public namespaces of gcr.io, quay.io and ghcr.io) have imagePullSecrets, on the pod or on its ServiceAccount.",
+		Why:         "Without credentials a private registry refuses the pull and the pod sits in ImagePullBackOff, often only noticed when a node without the image cached gets the pod.",
+		Remediation: "Add the registry secret to spec.template.spec.imagePullSecrets, or to the ServiceAccount's imagePullSecrets so every pod using it gets it.",
+	},
+	{
+		Name:        "Container Port Names",
+		Checks:      "Opt-in: every port of the app containers (sidecars excluded) has a name.",
+		Why:         "A Service targeting the port by name keeps working when the container port number changes, and named ports document what each port is for. It's the container-side counterpart of the Istio Service port naming rule.",
+		Remediation: "Name each entry of the container's ports, e.g. name: http-web, and point the Service's targetPort at that name.",
+	},
+	{
+		Name:        "Stuck Rollout",
+		Checks:      "The app's live pods (not completed, failed or terminating) don't carry more than one pod-template-hash for longer than 15 minutes after the newest hash's first pod was created. The description lists the pods per hash.",
+		Why:         "A rollout stuck at \"2 old, 1 new\" keeps serving the old version from a mix of ReplicaSets for hours, while the Deployment and pod panels look steady.",
+		Remediation: "Check kubectl rollout status deployment <name> and the new pods' events (failing probes, image pulls, quota), then fix forward or kubectl rollout undo.",
+	},
+	{
+		Name:        "Node Spread",
+		Checks:      "When two or more pods are running, they are placed on more than one node (actual placement, not the anti-affinity spec).",
+		Why:         "A \"preferred\" anti-affinity or a tight cluster can still land every replica on the same node. Losing that node then takes the whole app down.",
+		Remediation: "Use a requiredDuringScheduling podAntiAffinity or a topologySpreadConstraint on kubernetes.io/hostname, then restart the pods to reschedule them.",
+	},
+	{
+		Name:        "Zone Spread",
+		Checks:      "On clusters whose nodes span several topology.kubernetes.io/zone values, the running pods are placed in more than one zone.",
+		Why:         "Topology spread constraints can be satisfied on paper while scheduling restrictions (node selectors, capacity) still put every pod in one zone. A zone outage then takes the whole app down.",
+		Remediation: "Add a topologySpreadConstraint on topology.kubernetes.io/zone with whenUnsatisfiable: DoNotSchedule, or relax node selectors, then restart the pods.",
+	},
+	{
+		Name:        "Sidecar Injection",
+		Checks:      "Pods in a namespace labeled istio-injection=enabled (or istio.io/rev), or annotated sidecar.istio.io/inject=true, actually run the istio-proxy container.",
+		Why:         "A pod that should be in the mesh but has no sidecar silently drops out of mTLS, and strict PeerAuthentication then rejects its traffic.",
+		Remediation: "Restart the pods (kubectl rollout restart deployment <name>) so the injector runs, and check the injection webhook is healthy.",
+	},
+	{
+		Name:        "Istio Annotations",
+		Checks:      "The pods' sidecar.istio.io/inject annotation and label agree and don't override a namespace enabling injection, sidecar and traffic annotations only appear on injected pods, and no port is both included and excluded by the traffic.sidecar.istio.io port lists. Shows the effective injection decision.",
+		Why:         "A pod-level inject: false silently wins over namespace injection: the pod runs without a sidecar, falls out of the mesh and breaks mTLS for its callers. Contradicting annotations are ignored just as silently.",
+		Remediation: "Remove the pod-level sidecar.istio.io/inject override (or the namespace injection if the app really must stay out of the mesh), and keep each port in only one of the include/exclude lists.",
+	},
+	{
+		Name:        "Referenced Config",
+		Checks:      "Every ConfigMap and Secret a pod references through envFrom, env valueFrom or volumes exists in the namespace, unless the reference is marked optional.",
+		Why:         "A missing ConfigMap or Secret keeps the pod in ContainerCreating (or CreateContainerConfigError) and the cause is easy to miss in the pod status.",
+		Remediation: "Create the missing ConfigMap or Secret, fix the name in the Deployment, or mark the reference optional: true if the app can start without it.",
+	},
+	{
+		Name:        "PVC Bound",
+		Checks:      "Every PersistentVolumeClaim mounted by the app's pods exists and is Bound.",
+		Why:         "A Pending or missing claim keeps the pods in ContainerCreating, and the pod status alone doesn't say why.",
+		Remediation: "Check the claim's events (kubectl describe pvc): fix the storage class, capacity or access mode, or create the missing claim.",
+	},
+	{
+		Name:        "Deployment Labels",
+		Checks:      "The Deployment carries the app and version labels.",
+		Why:         "Istio telemetry and many dashboards group workloads by app and version. Without them metrics can't be attributed to a release.",
+		Remediation: "Add metadata.labels.app and metadata.labels.version to the Deployment (and its pod template).",
+	},
+	{
+		Name:        "Deployment Annotations",
+		Checks:      "The Deployment or its pod template carries every annotation listed in -required-annotations.",
+		Why:         "Alerting and scraping are driven by annotations such as prometheus.io/scrape or an owner contact. When they are missing the app silently drops out of monitoring.",
+		Remediation: "Add the missing keys under metadata.annotations of the Deployment or spec.template.metadata.annotations.",
+	},
+	{
+		Name:        "GitOps Ownership",
+		Checks:      "Opt-in: enabled with -enable-rules \"GitOps Ownership\". The Deployment carries one of the GitOps ownership labels or annotations (-gitops-markers, by default the Argo CD instance label or tracking-id annotation and the Flux kustomize/helm labels).",
+		Why:         "Deployments applied by hand bypass review, drift from what is in Git and get overwritten or lost on the next sync or cluster rebuild.",
+		Remediation: "Move the manifests into the GitOps repository and let Argo CD or Flux adopt the Deployment, then remove the hand-applied copy if the names differ.",
+	},
+	{
+		Name:        "Progress Deadline",
+		Checks:      "The Deployment sets progressDeadlineSeconds to a value other than the Kubernetes default (600), no larger than -max-progress-deadline.",
+		Why:         "Until the deadline passes a broken rollout just shows as progressing. A deliberately tuned deadline makes the Deployment report ProgressDeadlineExceeded, which rollout tooling uses to alert or roll back.",
+		Remediation: "Set spec.progressDeadlineSeconds on the Deployment to slightly more than the worst-case rollout time of the app.",
+	},
+	{
+		Name:        "Replica Count",
+		Checks:      "The Deployment's spec.replicas (1 when unset) is at least -min-replicas (default 2).",
+		Why:         "A single replica is down for every rollout, node drain and crash: the app is unavailable until its replacement is scheduled, pulled and ready.",
+		Remediation: "Raise spec.replicas (or the HPA's minReplicas) to at least the minimum, and spread the replicas over nodes with podAntiAffinity or topologySpreadConstraints.",
+	},
+	{
+		Name:        "Max Unavailable",
+		Checks:      "The rolling update's maxUnavailable (25% when unset), resolved against spec.replicas and rounded down like the Deployment controller does, takes down at most a third of the replicas at once. Recreate deployments are not checked.",
+		Why:         "Percentages hide how coarse small deployments are: maxUnavailable 50% on 2 replicas stops half the app at once, so a rollout halves capacity and a bad pod takes out the rest.",
+		Remediation: "Lower spec.strategy.rollingUpdate.maxUnavailable (e.g. 0 with maxSurge 1 for small deployments) or run more replicas.",
+	},
+	{
+		Name:        "Anti-Affinity Selector",
+		Checks:      "Every podAntiAffinity term (required or preferred) of the Deployment's pod template has a labelSelector matching the template's own labels, and doesn't only look at other namespaces. Deployments without podAntiAffinity pass.",
+		Why:         "Anti-affinity copied from another app keeps pointing at that app's label: the scheduler keeps this app's replicas away from the other app's pods instead of from each other, which does nothing useful and gives false confidence.",
+		Remediation: "Point the podAntiAffinity labelSelector at the app's own pod labels, e.g. matchLabels app: <name>.",
+	},
+	{
+		Name:        "Ready Replicas",
+		Checks:      "At least -min-ready-percent (default 100) percent of the Deployment's spec.replicas (1 when unset) have a pod whose Ready condition is true. Terminating pods don't count; deployments scaled to zero pass. Skipped with -manifests, where no pods run.",
+		Why:         "A pod that is Running but not Ready gets no traffic: 1 of 3 ready looks up in the pod list while the app runs on a third of its capacity.",
+		Remediation: "Look at the unready pods' readiness probe failures and events. For a canary that deliberately runs partly unready, lower -min-ready-percent.",
+	},
+	{
+		Name:        "OOMKilled",
+		Checks:      "No container of any pod (init containers included) is terminated, or was last terminated, with reason OOMKilled. The container, exit code and restart count are listed.",
+		Why:         "A container exceeding its memory limit is killed by the kernel and restarted. The pod looks Running again afterwards, so the crash only shows in the container's last termination state.",
+		Remediation: "Raise the container's memory limit (and request) to cover its peak usage, or fix the leak; for JVMs, keep the heap (-Xmx or MaxRAMPercentage) well below the limit.",
+	},
+	{
+		Name:        "Tolerations",
+		Checks:      "No pod toleration uses operator Exists without a key, which tolerates every taint (or every taint of its effect), and none tolerates node.kubernetes.io/unschedulable. The offending tolerations are listed.",
+		Why:         "A blanket toleration lets the app schedule onto nodes tainted to keep it away: dedicated GPU or tenant nodes (wasting capacity reserved for others) and nodes cordoned for maintenance.",
+		Remediation: "Replace the blanket toleration with tolerations for the specific taint keys the app is meant to tolerate, e.g. key: dedicated, operator: Equal, value: batch.",
+	},
+	{
+		Name:        "HPA Replica Conflict",
+		Checks:      "When a HorizontalPodAutoscaler targets the Deployment, spec.replicas lies within the HPA's min/max and the applied manifest doesn't pin replicas. The applied replicas come from kubectl's last-applied-configuration, else from a field manager other than the HPA owning spec.replicas (server-side apply, Helm); with -manifests the manifest's own spec.replicas.",
+		Why:         "Every apply of a manifest that sets replicas resets the Deployment, and the HPA scales it back again. The two fight and the pods thrash.",
+		Remediation: "Remove spec.replicas from the Deployment manifest managed by kubectl/GitOps and let the HPA own the replica count.",
+	},
+	{
+		Name:        "HPA Initial Replicas",
+		Checks:      "Only when a HorizontalPodAutoscaler targets the Deployment and the applied manifest sets replicas: every deploy resets the Deployment to them, so they must be at least half the HPA's minReplicas and at most its maxReplicas. The applied replicas are found as for HPA Replica Conflict; spec.replicas on its own isn't checked, the HPA rewrites it.",
+		Why:         "A deploy resetting the Deployment to 1 replica under an HPA min of 6 puts all the traffic on one pod until the HPA catches up, then scales up in a burst of pod starts right after every deploy.",
+		Remediation: "Remove replicas from the Deployment manifest, as HPA Replica Conflict also asks, so deploys keep the count the HPA scaled to.",
+	},
+	{
+		Name:        "HPA Metrics",
+		Checks:      "Only when a HorizontalPodAutoscaler targets the Deployment: every metric it scales on has a current value in its status (kubectl shows <unknown> otherwise) and its ScalingActive condition isn't False. The unknown metrics and the condition's reason are listed. Skipped with -manifests and until the HPA controller has reported a status.",
+		Why:         "An HPA that can't read its metrics keeps the current replica count: it silently can't scale, which is as bad as having none, and only shows in kubectl get hpa.",
+		Remediation: "Check metrics-server (kubectl top pods) or the custom/external metrics adapter serving the metric, and that the containers set the resource requests a utilization target needs.",
+	},
+	{
+		Name:        "Static Replicas",
+		Checks:      "Opt-in. A Deployment not targeted by a HorizontalPodAutoscaler runs at most -max-static-replicas (default 10) replicas, per spec.replicas.",
+		Why:         "A large fixed replica count is sized for a peak, or for a load the app had once, and runs at that size around the clock; cost reviews keep finding such statically over-scaled Deployments, and under-provisioned ones fall over at the next peak.",
+		Remediation: "Add a HorizontalPodAutoscaler on CPU or a request-rate metric with the current count as maxReplicas, and remove spec.replicas from the manifest; or lower the count to what the load needs.",
+	},
+	{
+		Name:        "Service Port Naming",
+		Checks:      "Every Service port name starts with a protocol Istio understands: http, http2, https, tcp, tls, grpc, mongo or redis (e.g. http-web).",
+		Why:         "Istio uses the port name to select the protocol. An unrecognised name is treated as opaque TCP and loses HTTP routing, retries and telemetry.",
+		Remediation: "Rename the Service ports to <protocol>[-<suffix>], e.g. http-api or grpc.",
+	},
+	{
+		Name:        "Port Protocol Consistency",
+		Checks:      "The protocol each Service port declares (its appProtocol, or else its name prefix) agrees with the name prefix, runs over TCP, and matches the prefix of the container port it targets when that port has an Istio-style name. http2 is accepted alongside grpc, and https alongside tls.",
+		Why:         "Istio picks the protocol from appProtocol before the port name, so contradictory hints (e.g. http-web with appProtocol: grpc) silently change routing and telemetry from what the name suggests.",
+		Remediation: "Make the port name prefix, appProtocol and container port name agree, or drop the appProtocol that contradicts the others.",
+	},
+	{
+		Name:        "Naming Convention",
+		Checks:      "Opt-in. The Deployment name, the Service name and the app label value of the pod template are equal, or, with -naming-pattern, each matches that regular expression entirely. The divergent names are listed.",
+		Why:         "When a Deployment, its Service and its app label are named differently the resources of an app can't be correlated by name, in dashboards or by hand; this tool's own fallbacks to the Argo CD instance label exist because of it.",
+		Remediation: "Rename the divergent resource or relabel the pod template to follow the convention; renaming a Service or Deployment means recreating it, so plan it with the next migration.",
+	},
+	{
+		Name:        "Service scrape_tls Label",
+		Checks:      "The Service carries the label scrape_tls=true.",
+		Why:         "The monitoring stack only scrapes mesh workloads over TLS when the Service is labeled. Without it Prometheus scrapes in plain text and the target shows as down.",
+		Remediation: "kubectl label service <name> scrape_tls=true -n <namespace>, and add the label to the Service manifest.",
+	},
+	{
+		Name:        "Ready Endpoints Only",
+		Checks:      "The Service does not set publishNotReadyAddresses: true, unless it is headless (clusterIP: None) as clustering apps need for peer discovery.",
+		Why:         "With publishNotReadyAddresses the Service sends traffic to pods that are starting or failing readiness, which users see as errors.",
+		Remediation: "Remove spec.publishNotReadyAddresses from the Service, or move it to a separate headless Service used only for peer discovery.",
+	},
+	{
+		Name:        "scrape_tls Consistency",
+		Checks:      "The scrape_tls label has the same value on the Service, the Deployment and the Deployment's pod template.",
+		Why:         "Scrape targets are discovered from both the Service and the pods. When the values disagree Prometheus scrapes with the wrong TLS expectation and the target goes down.",
+		Remediation: "Set the same scrape_tls value on the Service, metadata.labels and spec.template.metadata.labels of the Deployment.",
+	},
+	{
+		Name:        "ServiceMonitor",
+		Checks:      "Only when the Service has scrape_tls: \"true\" and the Prometheus Operator ServiceMonitor CRD is installed: a ServiceMonitor in any namespace selects the Service through its selector and namespaceSelector.",
+		Why:         "The Prometheus Operator only scrapes Services selected by a ServiceMonitor. A Service labeled for scraping without one is a silent monitoring gap: no target, no alert.",
+		Remediation: "Create a ServiceMonitor whose spec.selector matches the Service labels, with a namespaceSelector covering the Service's namespace when it lives elsewhere.",
+	},
+	{
+		Name:        "VirtualService",
+		Checks:      "Opt-in, and only when the Istio CRDs are installed: an Istio VirtualService in any namespace has an http, tls or tcp route whose destination host is the Service (its short name from the same namespace, or name.namespace[.svc.cluster.local]).",
+		Why:         "Apps exposed through the mesh are reached via a VirtualService bound to the gateway. Without one the Service may be healthy and still unreachable from outside.",
+		Remediation: "Create a VirtualService bound to the gateway with a route whose destination.host is the Service, e.g. my-app.my-namespace.svc.cluster.local.",
+	},
+	{
+		Name:        "Session Affinity",
+		Checks:      "Only for apps whose Service or Deployment carries k8s-rules-viewer/sticky-sessions: \"true\": the Service sets sessionAffinity: ClientIP.",
+		Why:         "Apps keeping per-client state in memory break in subtle ways when requests start spreading over pods, e.g. after sessionAffinity is accidentally dropped from a manifest.",
+		Remediation: "Set spec.sessionAffinity: ClientIP on the Service (and keep it in the manifest), or remove the sticky-sessions annotation if the app no longer needs it.",
+	},
+	{
+		Name:        "CronJob Policies",
+		Checks:      "Opt-in: CronJobs matching the app label set concurrencyPolicy to Forbid or Replace and set startingDeadlineSeconds.",
+		Why:         "With the default Allow policy slow runs overlap and pile up, and without a starting deadline a run missed during an outage can start much later than expected.",
+		Remediation: "Set spec.concurrencyPolicy: Forbid (or Replace) and spec.startingDeadlineSeconds on the CronJob.",
+	},
+	{
+		Name:        "Deprecated APIs",
+		Checks:      "The app's Deployment, Service and HPA were not written (per their field managers and kubectl last-applied-configuration) with an apiVersion deprecated or removed as of the cluster version.",
+		Why:         "Manifests and pipelines still using a deprecated apiVersion stop applying once the cluster is upgraded to the release that removes it.",
+		Remediation: "Move the manifests to the suggested apiVersion (kubectl convert can help) and re-apply them before upgrading the cluster.",
+	},
+	{
+		Name:        "Namespace Consistency",
+		Checks:      "With -all-namespaces, no namespace holds a Service of the app without a backing Deployment, nor a Deployment expecting replicas without any pods. A resource the app doesn't have, e.g. no Service for a worker, is skipped.",
+		Why:         "Services left behind after moving an app to another namespace select nothing, yet still show up in service discovery and confuse whoever debugs the app.",
+		Remediation: "Delete the leftover Service (or Deployment) from the namespace the app moved out of, or deploy the app again if the namespace should still run it.",
+	},
+}
+
+// GetRuleDoc returns the documentation of a built-in rule, matching the name case-insensitively
+func GetRuleDoc(name string) (RuleDoc, bool) {
+	for _, doc := range ruleDocs {
+		if strings.EqualFold(doc.Name, name) {
+			return doc, true
+		}
+	}
+	return RuleDoc{}, false
+}
+
+// ExplainRule returns a detailed explanation of a built-in rule for the -explain flag
+func ExplainRule(name string) (string, error) {
+	doc, found := GetRuleDoc(name)
+	if !found {
+		names := make([]string, len(ruleDocs))
+		for i, d := range ruleDocs {
+			names[i] = d.Name
+		}
+		return "", fmt.Errorf("unknown rule %q, available rules: %s", name, strings.Join(names, ", "))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s\n%s\n\n", doc.Name, strings.Repeat("=", len(doc.Name))))
+	sb.WriteString(fmt.Sprintf("What it checks:\n  %s\n\n", doc.Checks))
+	sb.WriteString(fmt.Sprintf("Why it matters:\n  %s\n\n", doc.Why))
+	sb.WriteString(fmt.Sprintf("How to fix:\n  %s\n", doc.Remediation))
+	return sb.String(), nil
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Fix commands are only generated for rules with a safe, deterministic fix: the target object
+// is known and the correct value doesn't need a human decision.
+
+// scrapeTLSLabelFixCommand labels the service with scrape_tls=true
+func scrapeTLSLabelFixCommand(service *corev1.Service, namespace string) string {
+	if service == nil {
+		return ""
+	}
+	return fmt.Sprintf("kubectl label svc %s scrape_tls=true --overwrite -n %s", service.Name, namespace)
+}
+
+// scrapeTLSConsistencyFixCommand copies the service's scrape_tls value onto the deployment and its
+// pod template. Nothing is generated when the service has no value to copy.
+func scrapeTLSConsistencyFixCommand(service *corev1.Service, deployment *appsv1.Deployment, namespace string) string {
+	if service == nil || deployment == nil {
+		return ""
+	}
+	value, exists := service.Labels["scrape_tls"]
+	if !exists {
+		return ""
+	}
+	return fmt.Sprintf(`kubectl patch deployment %s -n %s --type merge -p '{"metadata":{"labels":{"scrape_tls":%q}},"spec":{"template":{"metadata":{"labels":{"scrape_tls":%q}}}}}'`,
+		deployment.Name, namespace, value, value)
+}
+
+// progressDeadlineFixCommand sets progressDeadlineSeconds to the configured maximum. Nothing is
+// generated when the maximum is the Kubernetes default, which the rule rejects.
+func progressDeadlineFixCommand(deployment *appsv1.Deployment, namespace string, maxSeconds int32) string {
+	if deployment == nil || maxSeconds <= 0 || maxSeconds == defaultProgressDeadlineSeconds {
+		return ""
+	}
+	return fmt.Sprintf(`kubectl patch deployment %s -n %s --type merge -p '{"spec":{"progressDeadlineSeconds":%d}}'`,
+		deployment.Name, namespace, maxSeconds)
+}
+
+// sessionAffinityFixCommand sets the service's sessionAffinity to ClientIP
+func sessionAffinityFixCommand(service *corev1.Service, namespace string) string {
+	if service == nil {
+		return ""
+	}
+	return fmt.Sprintf(`kubectl patch svc %s -n %s --type merge -p '{"spec":{"sessionAffinity":"ClientIP"}}'`,
+		service.Name, namespace)
+}
+
+// publishNotReadyFixCommand stops the service from publishing not-ready pods
+func publishNotReadyFixCommand(service *corev1.Service, namespace string) string {
+	if service == nil {
+		return ""
+	}
+	return fmt.Sprintf(`kubectl patch svc %s -n %s --type merge -p '{"spec":{"publishNotReadyAddresses":false}}'`,
+		service.Name, namespace)
+}
@@ -1,11 +1,17 @@
 package tui
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
+	k8s "github.com/kiquetal/k8s-rules-viewer/internal/kubernetes"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -21,55 +27,193 @@ func GetKrakendConfigCheck() string {
 }
 
 // KrakenDBackendServiceCheck checks if a service is referenced in KrakenD backend configuration
-func KrakenDBackendServiceCheck(clientset *kubernetes.Clientset, namespace, configMapName, serviceName string) (string, error) {
+// The report starts with where in the ConfigMap the configuration was read from.
+func KrakenDBackendServiceCheck(clientset kubernetes.Interface, namespace, configMapName, serviceName string) (string, error) {
+	config, source, err := loadKrakenDConfig(clientset, namespace, configMapName)
+	if err != nil {
+		return "", err
+	}
+	references := findServiceReferences(config, serviceName)
+	result := fmt.Sprintf("Config: %s\n", source)
+	if len(references) == 0 {
+		return result + fmt.Sprintf("❌ Service '%s' not found in KrakenD backend configuration", serviceName), nil
+	}
+
+	// Build result string with references found
+	result += fmt.Sprintf("✅ Service '%s' found in %d backend configurations:\n", serviceName, len(references))
+	for i, ref := range references {
+		result += fmt.Sprintf("  %d. %s\n", i+1, ref)
+	}
+
+	return result, nil
+}
+
+// KrakenDBackendReferences returns the KrakenD endpoints whose backends reference the service
+func KrakenDBackendReferences(clientset kubernetes.Interface, namespace, configMapName, serviceName string) ([]string, error) {
+	config, _, err := loadKrakenDConfig(clientset, namespace, configMapName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for the service in backend configurations
+	return findServiceReferences(config, serviceName), nil
+}
+
+// loadKrakenDConfig fetches the ConfigMap and parses its KrakenD configuration, returning where it was read from
+func loadKrakenDConfig(clientset kubernetes.Interface, namespace, configMapName string) (map[string]interface{}, string, error) {
 	if clientset == nil {
-		return "", fmt.Errorf("kubernetes client not initialized")
+		return nil, "", fmt.Errorf("kubernetes client not initialized")
 	}
 
 	// Get the ConfigMap
 	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), configMapName, metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to get ConfigMap %s: %v", configMapName, err)
+		return nil, "", fmt.Errorf("failed to get ConfigMap %s: %v", configMapName, err)
 	}
 
-	// Check if the ConfigMap has the KrakenD configuration data
-	krakendConfig, exists := configMap.Data["krakend.json"]
-	if !exists {
-		// Try common alternative filenames
-		for key := range configMap.Data {
-			if strings.HasSuffix(key, ".json") {
-				krakendConfig = configMap.Data[key]
-				break
-			}
-		}
-		if krakendConfig == "" {
-			return "", fmt.Errorf("no JSON configuration found in ConfigMap %s", configMapName)
+	return parseKrakenDConfig(configMap)
+}
+
+// FindKrakenDConfigMap returns the name of the ConfigMap matching the label selector that holds a parseable
+// KrakenD config, the newest one when several do (e.g. ConfigMaps with a content hash suffix kept across deploys)
+func FindKrakenDConfigMap(clientset kubernetes.Interface, namespace, labelSelector string) (string, error) {
+	if clientset == nil {
+		return "", fmt.Errorf("kubernetes client not initialized")
+	}
+
+	items, err := k8s.GetConfigMapsByLabel(clientset, namespace, labelSelector)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("no ConfigMap matches %s", labelSelector)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+	})
+	for i := range items {
+		if _, _, err := parseKrakenDConfig(&items[i]); err == nil {
+			return items[i].Name, nil
 		}
 	}
+	return "", fmt.Errorf("none of the %d ConfigMaps matching %s holds a parseable KrakenD configuration", len(items), labelSelector)
+}
+
+// parseKrakenDConfig parses the KrakenD configuration of a ConfigMap, returning where it was read from,
+// e.g. data["krakend.json"] or binaryData["krakend.json.gz"] (gzip)
+func parseKrakenDConfig(configMap *corev1.ConfigMap) (map[string]interface{}, string, error) {
+	krakendConfig, source, err := krakendConfigData(configMap)
+	if err != nil {
+		return nil, "", err
+	}
 
 	// Parse the JSON configuration
 	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(krakendConfig), &config); err != nil {
-		return "", fmt.Errorf("failed to parse KrakenD configuration: %v", err)
+	if err := json.Unmarshal(krakendConfig, &config); err != nil {
+		return nil, "", fmt.Errorf("failed to parse KrakenD configuration from %s: %v", source, err)
 	}
+	return config, source, nil
+}
 
-	// Check for the service in backend configurations
-	references := findServiceReferences(config, serviceName)
-	if len(references) == 0 {
-		return fmt.Sprintf("❌ Service '%s' not found in KrakenD backend configuration", serviceName), nil
+// krakendConfigData returns the KrakenD configuration of a ConfigMap and where it was read from: its krakend.json
+// key or else any other .json key of data, then the same keys (optionally .gz) of binaryData. binaryData
+// entries are gunzipped when they are gzip-compressed.
+func krakendConfigData(configMap *corev1.ConfigMap) ([]byte, string, error) {
+	// Check if the ConfigMap has the KrakenD configuration data
+	if key := krakendConfigKey(configMap.Data); key != "" {
+		return []byte(configMap.Data[key]), fmt.Sprintf("data[%q]", key), nil
 	}
 
-	// Build result string with references found
-	result := fmt.Sprintf("✅ Service '%s' found in %d backend configurations:\n", serviceName, len(references))
-	for i, ref := range references {
-		result += fmt.Sprintf("  %d. %s\n", i+1, ref)
+	// Then the binary data, where configs are often stored compressed
+	key := krakendConfigKey(configMap.BinaryData)
+	if key == "" {
+		return nil, "", fmt.Errorf("no JSON configuration found in ConfigMap %s", configMap.Name)
+	}
+	content := configMap.BinaryData[key]
+	source := fmt.Sprintf("binaryData[%q]", key)
+	if !bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		return content, source, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decompress %s: %v", source, err)
+	}
+	defer reader.Close()
+	content, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decompress %s: %v", source, err)
 	}
+	return content, source + " (gzip)", nil
+}
 
-	return result, nil
+// krakendConfigKey returns the key holding the KrakenD configuration: krakend.json, krakend.json.gz, or else
+// the first (sorted) other .json or .json.gz key, "" when there is none
+func krakendConfigKey[V string | []byte](data map[string]V) string {
+	for _, key := range []string{"krakend.json", "krakend.json.gz"} {
+		if _, exists := data[key]; exists {
+			return key
+		}
+	}
+
+	// Try common alternative filenames
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if strings.HasSuffix(key, ".json") || strings.HasSuffix(key, ".json.gz") {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// serviceDiscoveryKeys are the keys of the service discovery settings of the config root and backends
+var serviceDiscoveryKeys = []string{"sd", "service_discovery"}
+
+// matchingStrings returns the strings found anywhere in value (a string, array or object)
+// that contain the service name, in order
+func matchingStrings(value interface{}, serviceName string) []string {
+	var matches []string
+	switch v := value.(type) {
+	case string:
+		if strings.Contains(v, serviceName) {
+			matches = append(matches, v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			matches = append(matches, matchingStrings(item, serviceName)...)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			matches = append(matches, matchingStrings(v[key], serviceName)...)
+		}
+	}
+	return matches
 }
 
-// findServiceReferences searches the KrakenD config for service references
-// by iterating through endpoints and backends (non-recursive approach)
+// serviceDiscoveryReferences returns the service discovery settings of a config object
+// (the root or a backend) mentioning the service, labeled with the key they were found under
+func serviceDiscoveryReferences(object map[string]interface{}, serviceName string) []string {
+	var references []string
+	for _, key := range serviceDiscoveryKeys {
+		for _, match := range matchingStrings(object[key], serviceName) {
+			references = append(references, fmt.Sprintf("Service Discovery (%s): %s", key, match))
+		}
+	}
+	return references
+}
+
+// findServiceReferences searches the KrakenD config for service references: the global hosts and
+// service discovery settings at the config root, then the endpoints' backends (non-recursive approach).
+// Each reference names its location, "Root" or the endpoint.
 func findServiceReferences(config interface{}, serviceName string) []string {
 	var references []string
 
@@ -79,6 +223,14 @@ func findServiceReferences(config interface{}, serviceName string) []string {
 		return references
 	}
 
+	// Global backend hosts, used by every backend that doesn't set its own
+	if hosts := matchingStrings(configMap["host"], serviceName); len(hosts) > 0 {
+		references = append(references, fmt.Sprintf("Root → Host: %s", hosts[0]))
+	}
+	for _, reference := range serviceDiscoveryReferences(configMap, serviceName) {
+		references = append(references, "Root → "+reference)
+	}
+
 	// Get the endpoints array
 	endpoints, ok := configMap["endpoints"].([]interface{})
 	if !ok {
@@ -142,8 +294,172 @@ func findServiceReferences(config interface{}, serviceName string) []string {
 			if found {
 				continue
 			}
+
+			for _, reference := range serviceDiscoveryReferences(backendMap, serviceName) {
+				references = append(references, fmt.Sprintf("Endpoint: %s → %s", endpointPath, reference))
+			}
 		}
 	}
 
 	return references
 }
+
+// KrakenDConfigDiff is the endpoint-level difference between a base KrakenD configuration and another one
+type KrakenDConfigDiff struct {
+	// Added and Removed list the endpoints ("METHOD /path") only in the other or only in the base config
+	Added, Removed []string
+	// Changed describes the endpoints in both whose backends differ, e.g. "GET /users: removed backend ..."
+	Changed []string
+	// AddedReferences and RemovedReferences list the service references only in the other or only in the base config
+	AddedReferences, RemovedReferences []string
+}
+
+// CompareKrakenDConfigMaps parses the KrakenD configurations of two ConfigMaps, e.g. of two environments,
+// and compares their endpoints and backends, and their references to the service
+func CompareKrakenDConfigMaps(clientset kubernetes.Interface, baseNamespace, baseName, otherNamespace, otherName, serviceName string) (KrakenDConfigDiff, error) {
+	base, _, err := loadKrakenDConfig(clientset, baseNamespace, baseName)
+	if err != nil {
+		return KrakenDConfigDiff{}, err
+	}
+	other, _, err := loadKrakenDConfig(clientset, otherNamespace, otherName)
+	if err != nil {
+		return KrakenDConfigDiff{}, err
+	}
+	return compareKrakenDConfigs(base, other, serviceName), nil
+}
+
+// compareKrakenDConfigs compares two parsed KrakenD configurations endpoint by endpoint
+func compareKrakenDConfigs(base, other map[string]interface{}, serviceName string) KrakenDConfigDiff {
+	var diff KrakenDConfigDiff
+	baseEndpoints, otherEndpoints := krakendEndpointBackends(base), krakendEndpointBackends(other)
+
+	for _, endpoint := range sortedKeys(baseEndpoints) {
+		otherBackends, exists := otherEndpoints[endpoint]
+		if !exists {
+			diff.Removed = append(diff.Removed, endpoint)
+			continue
+		}
+		removed, added := stringSetDifference(baseEndpoints[endpoint], otherBackends)
+		var changes []string
+		for _, backend := range removed {
+			changes = append(changes, "removed backend "+backend)
+		}
+		for _, backend := range added {
+			changes = append(changes, "added backend "+backend)
+		}
+		if len(changes) > 0 {
+			diff.Changed = append(diff.Changed, fmt.Sprintf("%s: %s", endpoint, strings.Join(changes, "; ")))
+		}
+	}
+	for _, endpoint := range sortedKeys(otherEndpoints) {
+		if _, exists := baseEndpoints[endpoint]; !exists {
+			diff.Added = append(diff.Added, endpoint)
+		}
+	}
+
+	diff.RemovedReferences, diff.AddedReferences = stringSetDifference(
+		findServiceReferences(base, serviceName), findServiceReferences(other, serviceName))
+	return diff
+}
+
+// krakendEndpointBackends returns the backends of each endpoint ("METHOD /path"), each described by its
+// method, hosts and url_pattern, e.g. "GET http://users-svc/users/{id}"
+func krakendEndpointBackends(config map[string]interface{}) map[string][]string {
+	endpoints := make(map[string][]string)
+	endpointList, _ := config["endpoints"].([]interface{})
+	for _, endpoint := range endpointList {
+		endpointMap, ok := endpoint.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _ := endpointMap["endpoint"].(string)
+		key := krakendMethod(endpointMap) + " " + path
+
+		backends := []string{}
+		backendList, _ := endpointMap["backend"].([]interface{})
+		for _, backend := range backendList {
+			backendMap, ok := backend.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			urlPattern, _ := backendMap["url_pattern"].(string)
+			hosts := matchingStrings(backendMap["host"], "")
+			backends = append(backends, fmt.Sprintf("%s %s%s", krakendMethod(backendMap), strings.Join(hosts, ","), urlPattern))
+		}
+		sort.Strings(backends)
+		endpoints[key] = backends
+	}
+	return endpoints
+}
+
+// krakendMethod returns the method of an endpoint or backend, GET (the KrakenD default) when unset
+func krakendMethod(object map[string]interface{}) string {
+	if method, _ := object["method"].(string); method != "" {
+		return strings.ToUpper(method)
+	}
+	return "GET"
+}
+
+// stringSetDifference returns the strings only in a and the strings only in b, in order
+func stringSetDifference(a, b []string) (onlyA, onlyB []string) {
+	inA, inB := make(map[string]bool), make(map[string]bool)
+	for _, s := range a {
+		inA[s] = true
+	}
+	for _, s := range b {
+		inB[s] = true
+	}
+	for _, s := range a {
+		if !inB[s] {
+			onlyA = append(onlyA, s)
+		}
+	}
+	for _, s := range b {
+		if !inA[s] {
+			onlyB = append(onlyB, s)
+		}
+	}
+	return onlyA, onlyB
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FormatKrakenDConfigDiff renders the comparison of the base and other ConfigMaps, e.g.
+// "+ GET /orders" for an endpoint only in the other one
+func FormatKrakenDConfigDiff(diff KrakenDConfigDiff, base, other, serviceName string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("KrakenD config diff: %s → %s\n", base, other))
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed)+len(diff.AddedReferences)+len(diff.RemovedReferences) == 0 {
+		sb.WriteString("No endpoint differences\n")
+		return sb.String()
+	}
+
+	sections := []struct {
+		title, marker string
+		items         []string
+	}{
+		{"Added endpoints", "+", diff.Added},
+		{"Removed endpoints", "-", diff.Removed},
+		{"Changed endpoints", "~", diff.Changed},
+		{fmt.Sprintf("Added references to service '%s'", serviceName), "+", diff.AddedReferences},
+		{fmt.Sprintf("Removed references to service '%s'", serviceName), "-", diff.RemovedReferences},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", section.title, len(section.items)))
+		for _, item := range section.items {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", section.marker, item))
+		}
+	}
+	return sb.String()
+}
@@ -1,116 +1,562 @@
 package tui
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"io"
+	"regexp"
+	"slices"
+
+	k8s "github.com/kiquetal/k8s-rules-viewer/internal/kubernetes"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"strings"
-	_ "sync"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
-// DisplayLogsInTUI displays logs in the terminal user interface
-func DisplayLogsInTUI(clientset *kubernetes.Clientset, namespace, podName, containerName string, app *tview.Application) {
-	// Create a new textview for logs
+// logReconnectDelay is how long to wait before re-opening a dropped log stream
+const logReconnectDelay = 2 * time.Second
+
+// maxLogReconnectAttempts is the number of consecutive failed reconnects, or streams that
+// ended while the container kept running, before giving up
+const maxLogReconnectAttempts = 5
+
+// DefaultLogReadBufferSize is the default size in bytes of the log read buffer, the longest line
+// shown whole in the log views
+const DefaultLogReadBufferSize = 64 * 1024
+
+// logReadBufferSize is the size of the log read buffer, see SetLogReadBufferSize
+var logReadBufferSize = DefaultLogReadBufferSize
+
+// SetLogReadBufferSize sets the size in bytes of the log read buffer. Longer lines are split.
+func SetLogReadBufferSize(size int) {
+	logReadBufferSize = size
+}
+
+// logHighlight matches the -highlight patterns, nil when there are none
+var logHighlight *regexp.Regexp
+
+// SetLogHighlights compiles the patterns whose matches are highlighted in the log views,
+// e.g. a request ID followed through the logs. Call it once before showing logs.
+func SetLogHighlights(patterns []string) error {
+	if len(patterns) == 0 {
+		logHighlight = nil
+		return nil
+	}
+	// One alternation keeps a highlight from matching inside the tags added for another
+	alternatives := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid highlight pattern %q: %v", pattern, err)
+		}
+		alternatives[i] = "(?:" + pattern + ")"
+	}
+	logHighlight = regexp.MustCompile(strings.Join(alternatives, "|"))
+	return nil
+}
+
+// highlightLogContent colors the highlighted matches of a log line on a distinct background,
+// restoring the line's own color after each of them
+func highlightLogContent(content, color string) string {
+	if logHighlight == nil {
+		return content
+	}
+	return logHighlight.ReplaceAllStringFunc(content, func(match string) string {
+		if match == "" {
+			return match
+		}
+		return fmt.Sprintf("[black:aqua]%s[%s:-]", match, color)
+	})
+}
+
+// showLogTimestamps remembers for the rest of the session whether log views show timestamps
+var showLogTimestamps = true
+
+// LogTimestampsShown reports whether log views show timestamps, as last toggled with 't'
+func LogTimestampsShown() bool {
+	return showLogTimestamps
+}
+
+// SetLogTimestampsShown sets whether log views show timestamps, e.g. as saved by a previous session
+func SetLogTimestampsShown(show bool) {
+	showLogTimestamps = show
+}
+
+// maxLogBufferLines is the number of lines a log view keeps, the oldest ones being dropped beyond it
+const maxLogBufferLines = 10000
+
+// LogBuffer keeps the raw lines shown in a log view so they can be re-rendered, e.g. when
+// timestamps are toggled, without fetching the logs again. It keeps the last maxLogBufferLines lines.
+// It is safe for concurrent use.
+type LogBuffer struct {
+	mu    sync.Mutex
+	view  *tview.TextView
+	lines []logLine
+}
+
+// logLine is a raw log line, or a status marker (reconnects, errors) that is already formatted
+type logLine struct {
+	text   string
+	marker bool
+}
+
+// NewLogBuffer returns an empty buffer rendering into the view, which is capped to the same number of lines
+func NewLogBuffer(view *tview.TextView) *LogBuffer {
+	view.SetMaxLines(maxLogBufferLines)
+	return &LogBuffer{view: view}
+}
+
+// append adds a line, dropping the oldest ones beyond maxLogBufferLines
+func (b *LogBuffer) append(line logLine) {
+	b.lines = append(b.lines, line)
+	if over := len(b.lines) - maxLogBufferLines; over > 0 {
+		b.lines = b.lines[over:]
+	}
+}
+
+// AppendLogs adds raw log output and renders it
+func (b *LogBuffer) AppendLogs(raw string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.append(logLine{text: line})
+			fmt.Fprint(b.view, formatLogLine(line, showLogTimestamps))
+		}
+	}
+}
+
+// AppendMarker adds a formatted status line
+func (b *LogBuffer) AppendMarker(formatted string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.append(logLine{text: formatted, marker: true})
+	fmt.Fprint(b.view, formatted)
+}
+
+// Reset empties the buffer and the view
+func (b *LogBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = nil
+	b.view.Clear()
+}
+
+// ToggleTimestamps shows or hides the timestamp column and re-renders the buffered lines
+func (b *LogBuffer) ToggleTimestamps() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	showLogTimestamps = !showLogTimestamps
+
+	var sb strings.Builder
+	for _, line := range b.lines {
+		if line.marker {
+			sb.WriteString(line.text)
+		} else {
+			sb.WriteString(formatLogLine(line.text, showLogTimestamps))
+		}
+	}
+	b.view.SetText(sb.String())
+	b.view.ScrollToEnd()
+}
+
+// ResolveContainer returns the container of the pod whose whole name matches the regular
+// expression pattern. An empty pattern selects the pod's first app container.
+func ResolveContainer(clientset kubernetes.Interface, namespace, podName, pattern string) (string, error) {
+	containers, err := k8s.GetPodContainers(clientset, namespace, podName)
+	if err != nil {
+		return "", err
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("no containers found in pod %s", podName)
+	}
+	if pattern == "" {
+		return containers[0], nil
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return "", fmt.Errorf("invalid container pattern %q: %v", pattern, err)
+	}
+
+	var matches []string
+	for _, container := range containers {
+		if re.MatchString(container) {
+			matches = append(matches, container)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no container in pod %s matches %q (containers: %s)",
+			podName, pattern, strings.Join(containers, ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("container pattern %q is ambiguous in pod %s, it matches: %s",
+			pattern, podName, strings.Join(matches, ", "))
+	}
+}
+
+// DisplayLogsInTUI displays logs in the terminal user interface, starting with podNames[podIndex].
+// The container is picked in each pod with ResolveContainer(containerPattern), and the [ and ]
+// keys switch the stream to the previous/next pod of the list.
+// Streaming stops when ctx is cancelled, i.e. when the caller closes the view.
+func DisplayLogsInTUI(ctx context.Context, clientset kubernetes.Interface, namespace string, podNames []string, podIndex int,
+	containerPattern string, app *tview.Application) {
+	logView, buffer, flex := newLogScreen(ctx, app, "Press [ / ] to switch pod, t to toggle timestamps, w to toggle wrapping, Esc to return")
+
+	// Stream the selected pod, stopping the previous stream first
+	var cancelStream context.CancelFunc
+	showPod := func(index int) {
+		if cancelStream != nil {
+			cancelStream()
+		}
+		podIndex = index
+
+		var streamCtx context.Context
+		streamCtx, cancelStream = context.WithCancel(ctx)
+		buffer.Reset()
+		logView.SetTitle(fmt.Sprintf(" Logs: %s (pod %d/%d) ", podNames[index], index+1, len(podNames)))
+
+		go streamPodContainer(streamCtx, clientset, namespace, podNames[index], containerPattern, buffer, func(containerName string) {
+			app.QueueUpdateDraw(func() {
+				logView.SetTitle(fmt.Sprintf(" Logs: %s/%s (pod %d/%d) ", podNames[index], containerName, index+1, len(podNames)))
+			})
+		})
+	}
+
+	logView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 't' {
+			buffer.ToggleTimestamps()
+			return nil
+		}
+		if event.Key() == tcell.KeyRune && len(podNames) > 1 {
+			switch event.Rune() {
+			case ']':
+				showPod((podIndex + 1) % len(podNames))
+				return nil
+			case '[':
+				showPod((podIndex - 1 + len(podNames)) % len(podNames))
+				return nil
+			}
+		}
+		return event
+	})
+
+	// Set this as the root of the application
+	app.SetRoot(flex, true)
+
+	// Start streaming logs
+	showPod(podIndex)
+}
+
+// newLogScreen creates a bordered log view redrawing the app on each change, its buffer, and a
+// layout showing the view above the key hint. The view's wrap state is forgotten once ctx is
+// cancelled, i.e. when the screen is closed.
+func newLogScreen(ctx context.Context, app *tview.Application, hint string) (*tview.TextView, *LogBuffer, *tview.Flex) {
 	logView := tview.NewTextView().
 		SetDynamicColors(true).
 		SetChangedFunc(func() {
 			app.Draw()
 		})
-
 	logView.SetBorder(true)
-	logView.SetTitle(fmt.Sprintf(" Logs: %s/%s ", podName, containerName))
+	context.AfterFunc(ctx, func() {
+		app.QueueUpdate(func() { forgetWrap(logView) })
+	})
 
-	// Create a flex layout
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(logView, 0, 1, true).
 		AddItem(tview.NewTextView().
 			SetTextAlign(tview.AlignCenter).
-			SetText("Press Esc to return"), 1, 0, false)
+			SetText(hint), 1, 0, false)
+	return logView, NewLogBuffer(logView), flex
+}
 
-	// Set this as the root of the application
+// streamPodContainer resolves the container matching containerPattern in the pod, reports it to
+// onContainer and streams its logs into the buffer until ctx is cancelled
+func streamPodContainer(ctx context.Context, clientset kubernetes.Interface, namespace, podName, containerPattern string,
+	buffer *LogBuffer, onContainer func(containerName string)) {
+	// Container names may differ slightly between pods, so resolve them per pod
+	containerName, err := ResolveContainer(clientset, namespace, podName, containerPattern)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		buffer.AppendMarker(fmt.Sprintf("[red]%s[white]\n", tview.Escape(err.Error())))
+		return
+	}
+	onContainer(containerName)
+	StreamPodLogsToView(ctx, clientset, namespace, podName, containerName, buffer)
+}
+
+// logFollowPollInterval is how often the pod list is checked for a newer pod when following logs by label
+const logFollowPollInterval = 2 * time.Second
+
+// newestRunningPod returns the most recently created running pod that isn't being deleted,
+// "" when there is none
+func newestRunningPod(pods []v1.Pod) string {
+	var newest *v1.Pod
+	for i, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if newest == nil || pod.CreationTimestamp.After(newest.CreationTimestamp.Time) ||
+			(pod.CreationTimestamp.Equal(&newest.CreationTimestamp) && pod.Name > newest.Name) {
+			newest = &pods[i]
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.Name
+}
+
+// FollowLogsByLabel displays the logs of the newest running pod matching the label selector and
+// switches the stream to a newer pod as soon as one appears, so the logs keep flowing while a
+// rollout replaces the pods (like stern). The pod list is polled every logFollowPollInterval.
+// Streaming stops when ctx is cancelled, i.e. when the caller closes the view.
+func FollowLogsByLabel(ctx context.Context, clientset kubernetes.Interface, namespace, labelSelector, containerPattern string,
+	app *tview.Application) {
+	logView, buffer, flex := newLogScreen(ctx, app, "Following the newest pod. Press t to toggle timestamps, w to toggle wrapping, Esc to return")
+	logView.SetTitle(fmt.Sprintf(" Logs: %s (following) ", labelSelector))
+	logView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 't' {
+			buffer.ToggleTimestamps()
+			return nil
+		}
+		return event
+	})
 	app.SetRoot(flex, true)
 
-	// Start streaming logs in a goroutine
-	go StreamPodLogsToView(clientset, namespace, podName, containerName, logView)
+	go func() {
+		ticker := time.NewTicker(logFollowPollInterval)
+		defer ticker.Stop()
+		var current string
+		listed := false
+		cancelStream := func() {}
+		defer func() { cancelStream() }()
+		for {
+			// Keep the current stream when the pod list can't be fetched
+			pods, err := k8s.GetPodsByLabel(clientset, namespace, labelSelector)
+			if newest := newestRunningPod(pods); err == nil && (newest != current || !listed) {
+				listed = true
+				cancelStream()
+				if newest == "" {
+					buffer.AppendMarker(fmt.Sprintf("[yellow]%s[white]\n",
+						tview.Escape(fmt.Sprintf("[no running pod matches %s, waiting...]", labelSelector))))
+				} else {
+					buffer.AppendMarker(fmt.Sprintf("[yellow]%s[white]\n", tview.Escape(fmt.Sprintf("[following pod %s]", newest))))
+					streamCtx, cancel := context.WithCancel(ctx)
+					cancelStream = cancel
+					go streamPodContainer(streamCtx, clientset, namespace, newest, containerPattern, buffer, func(containerName string) {
+						app.QueueUpdateDraw(func() {
+							logView.SetTitle(fmt.Sprintf(" Logs: %s/%s (following %s) ", newest, containerName, labelSelector))
+						})
+					})
+				}
+				current = newest
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StreamPodLogsToView streams pod logs into a log buffer, re-establishing the
+// stream when it drops (e.g. during a container restart) until ctx is cancelled
+func StreamPodLogsToView(ctx context.Context, clientset kubernetes.Interface, namespace, podName, containerName string, buffer *LogBuffer) {
+	var sinceTime *metav1.Time
+	failedAttempts := 0
+
+	for {
+		opened, err := streamPodLogsOnce(ctx, clientset, namespace, podName, containerName, sinceTime, buffer)
+		if ctx.Err() != nil {
+			return // The view was closed
+		}
+
+		if opened && err == io.EOF {
+			// The container exited for good, there is nothing left to follow
+			if containerFinished(ctx, clientset, namespace, podName, containerName) {
+				buffer.AppendMarker(fmt.Sprintf("\n[yellow]%s[white]\n", tview.Escape("[stream ended]")))
+				return
+			}
+			// Repeated EOFs count towards the cap even though each stream opened
+			failedAttempts++
+			if failedAttempts >= maxLogReconnectAttempts {
+				buffer.AppendMarker(fmt.Sprintf("\n[yellow]%s[white]\n", tview.Escape("[stream ended, giving up after repeated disconnects]")))
+				return
+			}
+			buffer.AppendMarker(fmt.Sprintf("\n[yellow]%s[white]\n", tview.Escape("[stream ended, reconnecting...]")))
+		} else if opened {
+			failedAttempts = 0
+			buffer.AppendMarker(fmt.Sprintf("\n[red]Error reading logs: %s[white]\n", tview.Escape(err.Error())))
+		} else {
+			failedAttempts++
+			if failedAttempts >= maxLogReconnectAttempts {
+				buffer.AppendMarker(fmt.Sprintf("\n[red]Error getting logs: %s[white]\n", tview.Escape(err.Error())))
+				return
+			}
+		}
+
+		// Resume from the drop instead of replaying the whole log
+		if opened {
+			sinceTime = &metav1.Time{Time: time.Now()}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logReconnectDelay):
+		}
+	}
 }
 
-// StreamPodLogsToView streams pod logs to a TextView component
-func StreamPodLogsToView(clientset *kubernetes.Clientset, namespace, podName, containerName string, textView *tview.TextView) {
+// containerFinished reports whether the container has exited and will not be restarted,
+// because the pod is gone, has completed, or its restart policy leaves the container stopped
+func containerFinished(ctx context.Context, clientset kubernetes.Interface, namespace, podName, containerName string) bool {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return apierrors.IsNotFound(err)
+	}
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return true
+	}
+
+	statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses)
+	for _, status := range statuses {
+		if status.Name != containerName {
+			continue
+		}
+		terminated := status.State.Terminated
+		if terminated == nil {
+			return false
+		}
+		switch pod.Spec.RestartPolicy {
+		case v1.RestartPolicyNever:
+			return true
+		case v1.RestartPolicyOnFailure:
+			return terminated.ExitCode == 0
+		}
+		return false
+	}
+	return false
+}
+
+// streamPodLogsOnce follows the logs until the stream ends. It reports whether the stream
+// could be opened, along with the error that ended it.
+func streamPodLogsOnce(ctx context.Context, clientset kubernetes.Interface, namespace, podName, containerName string,
+	sinceTime *metav1.Time, buffer *LogBuffer) (bool, error) {
 	req := clientset.CoreV1().Pods(namespace).GetLogs(podName, &v1.PodLogOptions{
 		Container:  containerName,
 		Follow:     true,
 		Timestamps: true,
+		SinceTime:  sinceTime,
 	})
 
-	readCloser, err := req.Stream(context.TODO())
+	readCloser, err := req.Stream(ctx)
 	if err != nil {
-		textView.SetText(fmt.Sprintf("Error getting logs: %v", err))
-		return
+		return false, err
 	}
 	defer readCloser.Close()
 
-	// Buffer for reading
-	buf := make([]byte, 4096)
+	if sinceTime != nil {
+		buffer.AppendMarker(fmt.Sprintf("[yellow]%s[white]\n", tview.Escape("[reconnected]")))
+	}
 
+	// Read whole lines, so neither a line nor a UTF-8 rune is split across reads
+	reader := bufio.NewReaderSize(readCloser, logReadBufferSize)
+	var carry []byte
 	for {
-		n, err := readCloser.Read(buf)
-		if err != nil {
-			if err != io.EOF {
-				textView.Write([]byte(fmt.Sprintf("\nError reading logs: %v", err)))
-			}
-			break
+		chunk, err := reader.ReadSlice('\n')
+		// Stop writing as soon as the view switched away from this stream
+		if ctx.Err() != nil {
+			return true, ctx.Err()
 		}
 
-		if n > 0 {
-			// Format the log entries with colors
-			logText := formatLogEntry(string(buf[:n]))
+		line := append(carry, chunk...)
+		carry = nil
+		if err == bufio.ErrBufferFull {
+			// Lines longer than the buffer are split, keeping a trailing partial rune for the next part
+			cut := completeRunesLength(line)
+			line, carry = line[:cut], slices.Clone(line[cut:])
+			err = nil
+		}
+		if len(line) > 0 {
+			// Append to the view, formatted with colors
+			buffer.AppendLogs(string(line))
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+}
 
-			// Append to the TextView
-			fmt.Fprint(textView, logText)
+// completeRunesLength returns the length of b without a trailing incomplete UTF-8 rune
+func completeRunesLength(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+			return i
 		}
 	}
+	return len(b)
 }
 
 // formatLogEntry adds colors and formatting to log entries
 func formatLogEntry(entry string) string {
 	// Split multi-line entries
 	lines := strings.Split(strings.TrimSpace(entry), "\n")
-	formattedLines := make([]string, 0, len(lines))
-
+	var sb strings.Builder
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
+		sb.WriteString(formatLogLine(line, true))
+	}
+	return sb.String()
+}
 
-		// Extract timestamp if present (assumes standard K8s log format)
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) == 2 {
-			// Try to parse the timestamp
-			timestamp := parts[0]
-			content := parts[1]
-
-			// Color code based on log content
-			if strings.Contains(strings.ToLower(content), "error") ||
-				strings.Contains(strings.ToLower(content), "exception") ||
-				strings.Contains(strings.ToLower(content), "fail") {
-				formattedLines = append(formattedLines, fmt.Sprintf("[gray]%s[white] [red]%s[white]", timestamp, content))
-			} else if strings.Contains(strings.ToLower(content), "warn") {
-				formattedLines = append(formattedLines, fmt.Sprintf("[gray]%s[white] [yellow]%s[white]", timestamp, content))
-			} else {
-				formattedLines = append(formattedLines, fmt.Sprintf("[gray]%s[white] %s", timestamp, content))
-			}
-		} else {
-			formattedLines = append(formattedLines, line)
-		}
+// formatLogLine colors a single log line by its content, with the leading timestamp
+// (standard K8s log format) in gray or hidden
+func formatLogLine(line string, showTimestamp bool) string {
+	// Extract timestamp if present (assumes standard K8s log format)
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) != 2 {
+		return line + "\n"
 	}
+	timestamp := parts[0]
+	content := parts[1]
 
-	return strings.Join(formattedLines, "\n") + "\n"
+	// Color code based on log content, then layer the highlights on top
+	lower := strings.ToLower(content)
+	if strings.Contains(lower, "error") || strings.Contains(lower, "exception") || strings.Contains(lower, "fail") {
+		content = fmt.Sprintf("[red]%s[white]", highlightLogContent(content, "red"))
+	} else if strings.Contains(lower, "warn") {
+		content = fmt.Sprintf("[yellow]%s[white]", highlightLogContent(content, "yellow"))
+	} else {
+		content = highlightLogContent(content, "white")
+	}
+
+	if !showTimestamp {
+		return content + "\n"
+	}
+	return fmt.Sprintf("[gray]%s[white] %s\n", timestamp, content)
 }
 
 // GetPodLogs returns the most recent logs from a pod as a formatted string
@@ -141,7 +587,7 @@ func GetPodLogsScreen() string {
 
 // FetchPodLogs fetches logs from a specific pod and container
 // Can be used to make GetPodLogs dynamic in the future
-func FetchPodLogs(clientset *kubernetes.Clientset, namespace, podName, containerName string, tailLines int64) (string, error) {
+func FetchPodLogs(clientset kubernetes.Interface, namespace, podName, containerName string, tailLines int64) (string, error) {
 	if clientset == nil {
 		return "Kubernetes client not initialized", nil
 	}
@@ -166,3 +612,9 @@ func FetchPodLogs(clientset *kubernetes.Clientset, namespace, podName, container
 
 	return formatLogEntry(buf.String()), nil
 }
+
+// StripColorTags removes the tview color and region tags from formatted text, e.g. the logs returned
+// by FetchPodLogs, for exporting it outside the TUI
+func StripColorTags(text string) string {
+	return tview.NewTextView().SetDynamicColors(true).SetRegions(true).SetText(text).GetText(true)
+}
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RulesReport is the JSON representation of one rules evaluation
+type RulesReport struct {
+	Namespace   string       `json:"namespace"`
+	App         string       `json:"app"`
+	EvaluatedAt time.Time    `json:"evaluated_at"`
+	Passed      int          `json:"passed"`
+	Failed      int          `json:"failed"`
+	Results     []RuleResult `json:"results"`
+}
+
+// NewRulesReport summarizes rule results for the JSON formatter
+func NewRulesReport(results []RuleResult, namespace, appLabel string, evaluatedAt time.Time) RulesReport {
+	report := RulesReport{
+		Namespace:   namespace,
+		App:         appLabel,
+		EvaluatedAt: evaluatedAt,
+		Results:     results,
+	}
+	for _, result := range results {
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// FormatRulesJSON renders rule results as an indented JSON report
+func FormatRulesJSON(results []RuleResult, namespace, appLabel string) (string, error) {
+	data, err := json.MarshalIndent(NewRulesReport(results, namespace, appLabel, time.Now()), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// FormatReportsJSON renders the results of several evaluations (e.g. one per app) as an indented JSON array
+func FormatReportsJSON(reports []RulesReport) (string, error) {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// FormatRulesJSONLine renders rule results as a single-line JSON report (one JSON Lines record)
+func FormatRulesJSONLine(results []RuleResult, namespace, appLabel string, evaluatedAt time.Time) (string, error) {
+	data, err := json.Marshal(NewRulesReport(results, namespace, appLabel, evaluatedAt))
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// prometheusLabelEscaper escapes label values for the Prometheus text format
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// FormatRulesPrometheus renders rule results in the Prometheus text exposition format
+func FormatRulesPrometheus(results []RuleResult, namespace, appLabel string) string {
+	var sb strings.Builder
+	ns := prometheusLabelEscaper.Replace(namespace)
+	app := prometheusLabelEscaper.Replace(appLabel)
+
+	sb.WriteString("# HELP k8s_rules_viewer_rule_passed Whether a compliance rule passed (1) or failed (0).\n")
+	sb.WriteString("# TYPE k8s_rules_viewer_rule_passed gauge\n")
+	failed := 0
+	for _, result := range results {
+		value := 0
+		if result.Passed {
+			value = 1
+		} else {
+			failed++
+		}
+		sb.WriteString(fmt.Sprintf("k8s_rules_viewer_rule_passed{namespace=\"%s\",app=\"%s\",rule=\"%s\",severity=\"%s\"} %d\n",
+			ns, app, prometheusLabelEscaper.Replace(result.Name), prometheusLabelEscaper.Replace(result.Severity), value))
+	}
+
+	sb.WriteString("# HELP k8s_rules_viewer_rules_failed Number of failing compliance rules.\n")
+	sb.WriteString("# TYPE k8s_rules_viewer_rules_failed gauge\n")
+	sb.WriteString(fmt.Sprintf("k8s_rules_viewer_rules_failed{namespace=\"%s\",app=\"%s\"} %d\n", ns, app, failed))
+	return sb.String()
+}
+
+// markdownCellEscaper keeps rule text from breaking out of a Markdown table cell
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// FormatRulesMarkdown renders rule results as a Markdown document: a heading, the pass/fail summary
+// and a table with one row per rule. Sections (e.g. configuration, logs) can be appended with "##" headings.
+func FormatRulesMarkdown(results []RuleResult, namespace, appLabel string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Rules report: %s (namespace %s)\n\n", appLabel, namespace))
+	sb.WriteString(fmt.Sprintf("Evaluated at %s. %s\n\n", time.Now().UTC().Format(time.RFC3339), rulesSummary(results)))
+
+	sb.WriteString("## Compliance\n\n")
+	sb.WriteString("| Rule | Severity | Result | Description |\n")
+	sb.WriteString("|------|----------|--------|-------------|\n")
+	for _, result := range results {
+		outcome := "FAIL"
+		if result.Passed {
+			outcome = "PASS"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", markdownCellEscaper.Replace(result.Name),
+			result.Severity, outcome, markdownCellEscaper.Replace(result.Description)))
+	}
+	return sb.String()
+}
+
+// FormatRulesCSV renders rule results as CSV with one row per rule
+func FormatRulesCSV(results []RuleResult, namespace, appLabel string) (string, error) {
+	return FormatReportsCSV([]RulesReport{NewRulesReport(results, namespace, appLabel, time.Now())})
+}
+
+// FormatReportsCSV renders the results of several evaluations (e.g. one per app) as one CSV
+// document with a single header
+func FormatReportsCSV(reports []RulesReport) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	if err := writer.Write([]string{"rule", "description", "passed", "severity", "namespace", "app"}); err != nil {
+		return "", err
+	}
+	for _, report := range reports {
+		for _, result := range report.Results {
+			record := []string{
+				result.Name,
+				result.Description,
+				strconv.FormatBool(result.Passed),
+				result.Severity,
+				report.Namespace,
+				report.App,
+			}
+			if err := writer.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -1,16 +1,35 @@
 package tui
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"log"
 	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"context"
 	"fmt"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+
+	k8s "github.com/kiquetal/k8s-rules-viewer/internal/kubernetes"
 )
 
 // Initialize logger at package level
@@ -26,13 +45,63 @@ func init() {
 }
 
 // StatusSymbols provides both emoji and text fallbacks for statuses
-type StatusSymbols struct {
-	Success string
-	Failure string
+type StatusSymbols = k8s.StatusSymbols
+
+// Symbol modes accepted by GetStatusSymbols
+const (
+	SymbolModeAuto  = "auto"
+	SymbolModeEmoji = "emoji"
+	SymbolModeASCII = "ascii"
+	SymbolModeNone  = "none"
+)
+
+// CustomStatusSymbols returns user-provided symbols, used verbatim without terminal detection;
+// an empty symbol falls back to the plain PASS/FAIL words. A custom failure symbol marks
+// failures of every severity.
+func CustomStatusSymbols(success, failure string) StatusSymbols {
+	symbols := GetStatusSymbols(SymbolModeNone)
+	if success != "" {
+		symbols.Success = success
+	}
+	if failure != "" {
+		symbols.Failure, symbols.Warning, symbols.Info = failure, "", ""
+	}
+	return symbols
+}
+
+// Status symbols of each mode, failures marked per severity
+var (
+	emojiSymbols = StatusSymbols{Success: "✅", Failure: "🔴", Warning: "🟡", Info: "🔵"}
+	asciiSymbols = StatusSymbols{Success: "[+]", Failure: "[!]", Warning: "[~]", Info: "[i]"}
+	plainSymbols = StatusSymbols{Success: "PASS", Failure: "FAIL", Warning: "WARN", Info: "INFO"}
+)
+
+// ResultSymbol returns the marker of a rule result: the success symbol when it passed, and
+// otherwise the failure symbol of its severity
+func ResultSymbol(symbols StatusSymbols, result RuleResult) string {
+	switch {
+	case result.Passed:
+		return symbols.Success
+	case result.Severity == SeverityWarning && symbols.Warning != "":
+		return symbols.Warning
+	case result.Severity == SeverityInfo && symbols.Info != "":
+		return symbols.Info
+	}
+	return symbols.Failure
 }
 
-// GetStatusSymbols returns appropriate status symbols based on terminal capabilities
-func GetStatusSymbols() StatusSymbols {
+// GetStatusSymbols returns the status symbols for the given mode: emoji, ascii, none (plain
+// PASS/FAIL words) or auto, which picks emoji or ascii based on terminal capabilities
+func GetStatusSymbols(mode string) StatusSymbols {
+	switch mode {
+	case SymbolModeEmoji:
+		return emojiSymbols
+	case SymbolModeASCII:
+		return asciiSymbols
+	case SymbolModeNone:
+		return plainSymbols
+	}
+
 	// Check if terminal likely supports emoji
 	// TERM_PROGRAM environment variable is set by many terminal emulators
 	termProgram := os.Getenv("TERM_PROGRAM")
@@ -62,24 +131,125 @@ func GetStatusSymbols() StatusSymbols {
 	}
 
 	if useEmoji {
-		return StatusSymbols{
-			Success: "✅",
-			Failure: "❌",
-		}
+		return emojiSymbols
 	}
 
 	// Fallback to ASCII symbols
-	return StatusSymbols{
-		Success: "[+]",
-		Failure: "[!]",
-	}
+	return asciiSymbols
 }
 
 // RuleResult represents the result of a rule validation
 type RuleResult struct {
-	Name        string
-	Description string
-	Passed      bool
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Passed      bool   `json:"passed"`
+	Severity    string `json:"severity"`
+	Remediation string `json:"remediation,omitempty"`
+	// FixCommand is a kubectl command that fixes a failing rule, set only when a safe deterministic fix exists
+	FixCommand string `json:"fix_command,omitempty"`
+}
+
+// RuleOptions holds the optional configuration used by EvaluateRules
+type RuleOptions struct {
+	// DynamicClient is used for rules targeting custom resources
+	DynamicClient dynamic.Interface
+	// UnstructuredRules are the custom resource rules loaded from the rules config
+	UnstructuredRules []UnstructuredRuleDefinition
+	// RequiredAnnotations enables the Deployment Annotations rule for these keys
+	RequiredAnnotations []string
+	// MaxProgressDeadlineSeconds is the largest acceptable Deployment progressDeadlineSeconds
+	MaxProgressDeadlineSeconds int32
+	// EnabledRules lists the opt-in (advisory) rules to evaluate, by name
+	EnabledRules []string
+	// MaxLimitRequestRatio is the largest acceptable limit/request ratio for the Resource Ratio rule,
+	// DefaultMaxLimitRequestRatio when zero
+	MaxLimitRequestRatio float64
+	// MinReadyPercent is the smallest acceptable percentage of the Deployment's replicas that are Ready for the
+	// Ready Replicas rule, DefaultMinReadyPercent when zero. Lower it for canary deployments.
+	MinReadyPercent int
+	// MinReplicas is the smallest replica count accepted by the Replica Count rule, DefaultMinReplicas when zero
+	MinReplicas int
+	// NamingPattern is a regular expression the Deployment name, Service name and app label value must
+	// match entirely for the Naming Convention rule; when empty they must be equal instead
+	NamingPattern string
+	// MaxStaticReplicas is the largest replica count of a Deployment without an HPA accepted by the Static
+	// Replicas rule, DefaultMaxStaticReplicas when zero
+	MaxStaticReplicas int32
+	// RulePlugin is an external command whose rule results are merged into the built-in ones (-rule-plugin),
+	// empty for none
+	RulePlugin string
+	// RulePluginTimeout bounds each run of the RulePlugin, DefaultRulePluginTimeout when zero
+	RulePluginTimeout time.Duration
+	// Redactor masks cluster-identifying details in the results (-redact), nil to keep them
+	Redactor *k8s.Redactor
+	// GitOpsMarkers are the label/annotation keys accepted as GitOps ownership by the GitOps Ownership
+	// rule, DefaultGitOpsMarkers when empty
+	GitOpsMarkers []string
+	// Manifests is set when the rules evaluate rendered manifests (-manifests) instead of the live cluster:
+	// the rules depending on live state, such as Ready Replicas, are skipped
+	Manifests bool
+	// PublicRegistries are the registry hosts, or host/namespace prefixes, the Image Pull Secrets rule pulls
+	// from without credentials, DefaultPublicRegistries when empty
+	PublicRegistries []string
+}
+
+// DefaultGitOpsMarkers are the labels and annotations set by Argo CD and Flux on the resources they manage
+var DefaultGitOpsMarkers = []string{
+	"argocd.argoproj.io/instance",
+	"argocd.argoproj.io/tracking-id",
+	"kustomize.toolkit.fluxcd.io/name",
+	"helm.toolkit.fluxcd.io/name",
+}
+
+// DefaultMaxLimitRequestRatio is the limit/request ratio above which the Resource Ratio rule flags a container
+const DefaultMaxLimitRequestRatio = 10.0
+
+// DefaultMinReplicas is the replica count below which the Replica Count rule flags a deployment as not highly available
+const DefaultMinReplicas = 2
+
+// DefaultMaxStaticReplicas is the replica count above which the Static Replicas rule expects an HPA
+const DefaultMaxStaticReplicas = 10
+
+// DefaultRulePluginTimeout is how long a -rule-plugin command may run before it is killed
+const DefaultRulePluginTimeout = 10 * time.Second
+
+// DefaultMinReadyPercent is the percentage of the Deployment's replicas that must be Ready for the Ready Replicas rule
+const DefaultMinReadyPercent = 100
+
+// ruleEnabled reports whether the named opt-in rule was enabled
+func (opts RuleOptions) ruleEnabled(name string) bool {
+	for _, enabled := range opts.EnabledRules {
+		if strings.EqualFold(enabled, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule severities, from most to least important
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// severityRanks orders the severities, the higher the more important
+var severityRanks = map[string]int{SeverityInfo: 1, SeverityWarning: 2, SeverityCritical: 3}
+
+// FailingRulesAtLeast returns the failing rules whose severity is the given one or more important,
+// e.g. only the critical ones for SeverityCritical. Rules without a severity count as info.
+func FailingRulesAtLeast(results []RuleResult, severity string) []RuleResult {
+	var failing []RuleResult
+	for _, result := range results {
+		rank, ok := severityRanks[result.Severity]
+		if !ok {
+			rank = severityRanks[SeverityInfo]
+		}
+		if !result.Passed && rank >= severityRanks[severity] {
+			failing = append(failing, result)
+		}
+	}
+	return failing
 }
 
 // ValidatePodServiceAccount checks if pod has a serviceAccountName (required for mTLS)
@@ -94,203 +264,2765 @@ func ValidatePodServiceAccount(pod *corev1.Pod, appLabel string) bool {
 	return false
 }
 
-// ValidateDeploymentLabels checks if deployment has required labels
-func ValidateDeploymentLabels(deployment *appsv1.Deployment) bool {
-	if deployment == nil || len(deployment.Labels) == 0 {
-		return false
+// podServiceAccountName returns the ServiceAccount a pod runs as, "default" when none is set
+func podServiceAccountName(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName == "" {
+		return "default"
 	}
+	return pod.Spec.ServiceAccountName
+}
 
-	// Check for required labels (app and version are commonly required)
-	requiredLabels := []string{"app", "version"}
-	for _, label := range requiredLabels {
-		if _, exists := deployment.Labels[label]; !exists {
-			return false
+// ValidateNotDefaultServiceAccount checks the pod doesn't run as the namespace's default ServiceAccount
+func ValidateNotDefaultServiceAccount(pod *corev1.Pod) bool {
+	return pod != nil && podServiceAccountName(pod) != "default"
+}
+
+// capabilityIssues returns the app containers that don't drop ALL capabilities, and the
+// capabilities added back by each container
+func capabilityIssues(pod *corev1.Pod) (notDropped, added []string) {
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		var capabilities *corev1.Capabilities
+		if container.SecurityContext != nil {
+			capabilities = container.SecurityContext.Capabilities
+		}
+		if capabilities == nil || !slices.Contains(capabilities.Drop, "ALL") {
+			notDropped = append(notDropped, container.Name)
+		}
+		if capabilities != nil && len(capabilities.Add) > 0 {
+			names := make([]string, len(capabilities.Add))
+			for i, capability := range capabilities.Add {
+				names[i] = string(capability)
+			}
+			added = append(added, fmt.Sprintf("%s adds %s", container.Name, strings.Join(names, ", ")))
 		}
 	}
+	return notDropped, added
+}
 
-	return true
+// ValidateDroppedCapabilities checks each app container's securityContext drops ALL capabilities,
+// adding back only the specific ones it needs
+func ValidateDroppedCapabilities(pod *corev1.Pod) bool {
+	notDropped, _ := capabilityIssues(pod)
+	return len(notDropped) == 0
 }
 
-// ValidateServicePortNaming checks if service ports follow Istio naming conventions
-func ValidateServicePortNaming(service *corev1.Service) bool {
-	if debugLog != nil {
-		debugLog.Printf("Validating service ports for service: %s", service.Name)
-		debugLog.Printf("Service ports: %+v", service.Spec.Ports)
-	}
-	if service == nil || len(service.Spec.Ports) == 0 {
-		return false
+// missingResources returns the app containers missing a CPU or memory request or limit, each with what
+// it misses, e.g. "app: cpu limit, memory request". Service mesh sidecars are left out.
+func missingResources(pod *corev1.Pod) []string {
+	var missing []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		var unset []string
+		for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, exists := container.Resources.Requests[resource]; !exists {
+				unset = append(unset, fmt.Sprintf("%s request", resource))
+			}
+			if _, exists := container.Resources.Limits[resource]; !exists {
+				unset = append(unset, fmt.Sprintf("%s limit", resource))
+			}
+		}
+		if len(unset) > 0 {
+			missing = append(missing, fmt.Sprintf("%s: %s", container.Name, strings.Join(unset, ", ")))
+		}
 	}
+	return missing
+}
 
-	validProtocols := []string{"http", "http2", "https", "tcp", "tls", "grpc", "mongo", "redis"}
+// ValidatePodResourceLimits checks every app container of the pod sets CPU and memory requests and limits
+func ValidatePodResourceLimits(pod *corev1.Pod) bool {
+	return pod != nil && len(missingResources(pod)) == 0
+}
 
-	for _, port := range service.Spec.Ports {
-		if port.Name == "" {
-			return false
+// resourceRatioIssues returns the app containers whose CPU or memory limit exceeds maxRatio times
+// the request, and separately those whose CPU limit equals the request, leaving no room to burst
+func resourceRatioIssues(pod *corev1.Pod, maxRatio float64) (oversized, noBurst []string) {
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
 		}
-
-		// Split the port name by "-" and check if the first part is a valid protocol
-		portNameParts := strings.Split(strings.ToLower(port.Name), "-")
-		if len(portNameParts) == 0 {
-			return false
+		for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			request, hasRequest := container.Resources.Requests[resource]
+			limit, hasLimit := container.Resources.Limits[resource]
+			if !hasRequest || !hasLimit || request.IsZero() {
+				continue
+			}
+			ratio := limit.AsApproximateFloat64() / request.AsApproximateFloat64()
+			if ratio > maxRatio {
+				oversized = append(oversized, fmt.Sprintf("%s %s limit %s is %.0fx the request %s",
+					container.Name, resource, limit.String(), ratio, request.String()))
+			}
+			if resource == corev1.ResourceCPU && limit.Cmp(request) == 0 {
+				noBurst = append(noBurst, container.Name)
+			}
 		}
+	}
+	return oversized, noBurst
+}
 
-		validProtocolFound := false
-		for _, protocol := range validProtocols {
-			if portNameParts[0] == protocol {
-				validProtocolFound = true
-				break
+// ValidateResourceRatio checks no app container sets a CPU or memory limit more than maxRatio times
+// its request, which packs nodes poorly and lets the container crowd out its neighbours when it bursts
+func ValidateResourceRatio(pod *corev1.Pod, maxRatio float64) bool {
+	oversized, _ := resourceRatioIssues(pod, maxRatio)
+	return len(oversized) == 0
+}
+
+// DefaultPublicRegistries are the registries commonly pulled from without credentials. gcr.io, quay.io and
+// ghcr.io host private repositories too, so only well-known public namespaces of them are listed.
+var DefaultPublicRegistries = []string{
+	"docker.io", "index.docker.io", "registry-1.docker.io", "registry.k8s.io", "k8s.gcr.io", "public.ecr.aws",
+	"mcr.microsoft.com", "gcr.io/distroless", "gcr.io/google-containers", "quay.io/prometheus", "quay.io/jetstack",
+	"ghcr.io/fluxcd",
+}
+
+// isPublicImage reports whether the image comes from one of the public registries: a registry host,
+// or a host and namespace prefix such as quay.io/prometheus
+func isPublicImage(image string, registries []string) bool {
+	for _, registry := range registries {
+		if strings.Contains(registry, "/") {
+			if strings.HasPrefix(image, strings.TrimSuffix(registry, "/")+"/") {
+				return true
 			}
+		} else if imageRegistry(image) == registry {
+			return true
 		}
+	}
+	return false
+}
 
-		if !validProtocolFound {
-			return false
+// imageRegistry returns the registry host of an image reference, docker.io when it has none
+func imageRegistry(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return host
+	}
+	return "docker.io"
+}
+
+// privateRegistryImages returns the pod's container images pulled from registries not in the public ones
+func privateRegistryImages(pod *corev1.Pod, publicRegistries []string) []string {
+	var images []string
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		if !isPublicImage(container.Image, publicRegistries) && !slices.Contains(images, container.Image) {
+			images = append(images, container.Image)
 		}
 	}
+	return images
+}
 
-	return true
+// mutableImageTags are the tags commonly moved to newer images: latest and branch or channel names
+var mutableImageTags = []string{
+	"latest", "main", "master", "develop", "dev", "development", "staging", "stage", "prod", "production",
+	"stable", "edge", "nightly", "snapshot", "canary", "release", "head", "trunk",
 }
 
-// ValidateServiceHasScrapeTLS checks if the service has the label "scrape_tls = true"
-func ValidateServiceHasScrapeTLS(service *corev1.Service) bool {
-	if service == nil || service.Labels == nil {
-		return false
+// imageTag returns the tag of an image reference, "" when it has none, and whether it is pinned by digest.
+// The tag is looked for after the last path component, so a registry port (registry:5000/app) isn't taken for one.
+func imageTag(image string) (string, bool) {
+	if strings.Contains(image, "@") {
+		return "", true
 	}
-	val, exists := service.Labels["scrape_tls"]
-	return exists && val == "true"
+	lastComponent := image[strings.LastIndex(image, "/")+1:]
+	_, tag, _ := strings.Cut(lastComponent, ":")
+	return tag, false
 }
 
-// EvaluateRules runs all validation rules against the resources in the namespace
-func EvaluateRules(clientset *kubernetes.Clientset, namespace string, appLabel string) []RuleResult {
-	if debugLog != nil {
-		debugLog.Printf("Starting evaluation with appLabel: %q in namespace: %q", appLabel, namespace)
+// mutableImages returns the pod's container images (init containers included) that aren't immutable: untagged,
+// :latest or tagged with a branch name, each with the reason, e.g. "registry:5000/app:latest uses a mutable tag"
+func mutableImages(pod *corev1.Pod) []string {
+	var images []string
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		tag, pinned := imageTag(container.Image)
+		var problem string
+		switch {
+		case pinned:
+			continue
+		case tag == "":
+			problem = fmt.Sprintf("%s is untagged", container.Image)
+		case slices.Contains(mutableImageTags, strings.ToLower(tag)):
+			problem = fmt.Sprintf("%s uses a mutable tag", container.Image)
+		default:
+			continue
+		}
+		if !slices.Contains(images, problem) {
+			images = append(images, problem)
+		}
 	}
+	return images
+}
 
-	results := []RuleResult{}
-	ctx := context.TODO()
+// ValidateImageTags checks every container image of the pod is immutable: pinned by digest, or tagged with
+// a tag other than latest or a branch name
+func ValidateImageTags(pod *corev1.Pod) bool {
+	return pod != nil && len(mutableImages(pod)) == 0
+}
 
-	// Rule 1: Check if pods have serviceAccountName (for mTLS)
-	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: appLabel,
-	})
-	if debugLog != nil {
-		debugLog.Printf("Pod list query result - Error: %v, Count: %d", err, len(podList.Items))
+// ValidateImagePullSecrets checks a pod pulling images from registries other than the public ones has an
+// imagePullSecrets entry, set on the pod or on its ServiceAccount (nil when not found)
+func ValidateImagePullSecrets(pod *corev1.Pod, serviceAccount *corev1.ServiceAccount, publicRegistries []string) bool {
+	if pod == nil {
+		return false
 	}
-	podServiceAccountValid := false
-	if err == nil && len(podList.Items) > 0 {
-		for _, pod := range podList.Items {
-			if ValidatePodServiceAccount(&pod, appLabel) {
-				podServiceAccountValid = true
-				break
+	return len(privateRegistryImages(pod, publicRegistries)) == 0 || len(pod.Spec.ImagePullSecrets) > 0 ||
+		(serviceAccount != nil && len(serviceAccount.ImagePullSecrets) > 0)
+}
+
+// unnamedContainerPorts lists the unnamed ports of the pod's app containers as "container: port, port"
+func unnamedContainerPorts(pod *corev1.Pod) []string {
+	var unnamed []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		var ports []string
+		for _, port := range container.Ports {
+			if port.Name == "" {
+				ports = append(ports, strconv.Itoa(int(port.ContainerPort)))
 			}
 		}
+		if len(ports) > 0 {
+			unnamed = append(unnamed, fmt.Sprintf("%s: %s", container.Name, strings.Join(ports, ", ")))
+		}
 	}
-	results = append(results, RuleResult{
-		Name:        "Service Account",
-		Description: "Pod serviceAccountName matches app label value",
-		Passed:      podServiceAccountValid,
-	})
+	return unnamed
+}
 
-	// Rule 2: Check if deployments have required labels
-	deploymentList, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: appLabel,
-	})
-	if debugLog != nil {
-		debugLog.Printf("Deployment list query result - Error: %v, Count: %d", err, len(deploymentList.Items))
+// ValidateContainerPortNames checks every port of the pod's app containers has a name, so Services
+// can target them by name and keep working when the port number changes
+func ValidateContainerPortNames(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
 	}
-	deploymentLabelsValid := false
-	if err == nil && len(deploymentList.Items) > 0 {
-		for _, deployment := range deploymentList.Items {
-			if ValidateDeploymentLabels(&deployment) {
-				deploymentLabelsValid = true
-				break
-			}
+	return len(unnamedContainerPorts(pod)) == 0
+}
+
+// missingProbes returns the app containers missing a liveness or readiness probe, each with the probes
+// it misses, e.g. "app: liveness, readiness". Service mesh sidecars are left out.
+func missingProbes(pod *corev1.Pod) []string {
+	var missing []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		var unset []string
+		if container.LivenessProbe == nil {
+			unset = append(unset, "liveness")
+		}
+		if container.ReadinessProbe == nil {
+			unset = append(unset, "readiness")
+		}
+		if len(unset) > 0 {
+			missing = append(missing, fmt.Sprintf("%s: %s", container.Name, strings.Join(unset, ", ")))
 		}
 	}
-	results = append(results, RuleResult{
-		Name:        "Deployment Labels",
-		Description: "Deployment has required labels (app, version)",
-		Passed:      deploymentLabelsValid,
-	})
+	return missing
+}
 
-	servicePortsValid := false
-	serviceScrapeTLSValid := false
-	if appLabel != "" {
-		// Clean the label and get the actual value
-		cleanLabel := strings.Trim(strings.TrimPrefix(appLabel, "app="), "\"")
+// ValidatePodProbes checks every app container of the pod defines both a liveness and a readiness probe
+func ValidatePodProbes(pod *corev1.Pod) bool {
+	return pod != nil && len(missingProbes(pod)) == 0
+}
+
+// minLivenessInitialDelaySeconds is the shortest liveness initialDelaySeconds
+// considered safe for a container that has no startupProbe to protect its boot
+const minLivenessInitialDelaySeconds = 10
 
-		// Try both app label and argocd instance label
-		labelSelectors := []string{
-			fmt.Sprintf("app=%s", cleanLabel),
-			fmt.Sprintf("argocd.argoproj.io/instance=%s", cleanLabel),
+// probeTimingIssues returns the app containers whose liveness probe could kill them during startup
+func probeTimingIssues(pod *corev1.Pod) []string {
+	var issues []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) || container.LivenessProbe == nil {
+			continue
+		}
+		// A startupProbe holds off the liveness probe until the app has booted
+		if container.StartupProbe != nil {
+			continue
+		}
+		if delay := container.LivenessProbe.InitialDelaySeconds; delay < minLivenessInitialDelaySeconds {
+			issues = append(issues, fmt.Sprintf("%s (liveness initialDelaySeconds: %d)", container.Name, delay))
 		}
+	}
+	return issues
+}
 
-		var service *corev1.Service
-		for _, selector := range labelSelectors {
-			if debugLog != nil {
-				debugLog.Printf("Trying service label selector: %s", selector)
-			}
+// ValidateProbeTimings checks that liveness probes leave containers enough time to start
+func ValidateProbeTimings(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	return len(probeTimingIssues(pod)) == 0
+}
 
-			serviceList, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
-				LabelSelector: selector,
-			})
-			if debugLog != nil {
-				debugLog.Printf("Service list query result for %s - Error: %v, Count: %d",
-					selector, err, len(serviceList.Items))
-			}
+// missingStartupProbes returns the app containers with a liveness probe but no startupProbe
+func missingStartupProbes(pod *corev1.Pod) []string {
+	var missing []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		if container.LivenessProbe != nil && container.StartupProbe == nil {
+			missing = append(missing, container.Name)
+		}
+	}
+	return missing
+}
 
-			if err == nil && len(serviceList.Items) > 0 {
-				service = &serviceList.Items[0]
-				if debugLog != nil {
-					debugLog.Printf("Found service: %s with labels: %v", service.Name, service.Labels)
-				}
-				break
+// ValidateStartupProbe checks that containers guarded by a liveness probe also define a startupProbe
+func ValidateStartupProbe(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	return len(missingStartupProbes(pod)) == 0
+}
+
+// aggressiveLivenessFailureThreshold is the failureThreshold at or below which a liveness
+// probe identical to the readiness probe restarts the container on a brief readiness blip
+const aggressiveLivenessFailureThreshold = 3
+
+// sharedProbeIssues returns the app containers reusing their readiness probe as an aggressive liveness probe
+func sharedProbeIssues(pod *corev1.Pod) []string {
+	var issues []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) || container.LivenessProbe == nil || container.ReadinessProbe == nil {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(container.LivenessProbe, container.ReadinessProbe) {
+			continue
+		}
+		// An unset failureThreshold defaults to 3
+		threshold := container.LivenessProbe.FailureThreshold
+		if threshold == 0 {
+			threshold = 3
+		}
+		if threshold <= aggressiveLivenessFailureThreshold {
+			issues = append(issues, fmt.Sprintf("%s (failureThreshold: %d)", container.Name, threshold))
+		}
+	}
+	return issues
+}
+
+// ValidateDistinctProbes checks that containers don't reuse the readiness probe as an aggressive liveness probe
+func ValidateDistinctProbes(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	return len(sharedProbeIssues(pod)) == 0
+}
+
+// danglingProbePorts returns the httpGet probes pointing at a port no container declares. Named ports
+// must be declared by the probed container itself; numeric ports may belong to any container, since
+// Istio rewrites app probes to the sidecar's port.
+func danglingProbePorts(pod *corev1.Pod) []string {
+	declared := make(map[int32]bool)
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			declared[port.ContainerPort] = true
+		}
+	}
+
+	var issues []string
+	for _, container := range pod.Spec.Containers {
+		if k8s.IsSidecarContainer(container.Name) {
+			continue
+		}
+		named := make(map[string]bool)
+		for _, port := range container.Ports {
+			if port.Name != "" {
+				named[port.Name] = true
 			}
 		}
 
-		if service != nil {
-			servicePortsValid = ValidateServicePortNaming(service)
-			serviceScrapeTLSValid = ValidateServiceHasScrapeTLS(service)
+		probes := []struct {
+			kind  string
+			probe *corev1.Probe
+		}{
+			{"readiness", container.ReadinessProbe},
+			{"liveness", container.LivenessProbe},
+			{"startup", container.StartupProbe},
+		}
+		for _, p := range probes {
+			if p.probe == nil || p.probe.HTTPGet == nil {
+				continue
+			}
+			port := p.probe.HTTPGet.Port
+			if port.Type == intstr.String && !named[port.StrVal] || port.Type == intstr.Int && !declared[port.IntVal] {
+				issues = append(issues, fmt.Sprintf("%s %s port %s", container.Name, p.kind, port.String()))
+			}
 		}
 	}
+	return issues
+}
 
-	results = append(results, RuleResult{
-		Name:        "Service Port Naming",
-		Description: fmt.Sprintf("Service (%s) ports follow Istio naming conventions", appLabel),
-		Passed:      servicePortsValid,
-	})
+// ValidateProbePorts checks that httpGet probes target a port declared by the pod's containers
+func ValidateProbePorts(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	return len(danglingProbePorts(pod)) == 0
+}
 
-	results = append(results, RuleResult{
-		Name:        "Service scrape_tls Label",
-		Description: fmt.Sprintf("Service (%s) has label scrape_tls = true", appLabel),
-		Passed:      serviceScrapeTLSValid,
-	})
+// podNodeDistribution counts the running pods per node
+func podNodeDistribution(pods []corev1.Pod) map[string]int {
+	distribution := make(map[string]int)
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName != "" {
+			distribution[pod.Spec.NodeName]++
+		}
+	}
+	return distribution
+}
 
-	return results
+// formatDistribution renders a count per key as "a: 2, b: 1", sorted by key
+func formatDistribution(distribution map[string]int) string {
+	keys := make([]string, 0, len(distribution))
+	for key := range distribution {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s: %d", key, distribution[key])
+	}
+	return strings.Join(parts, ", ")
 }
 
-// GetRulesCompliance evaluates all rules and returns a formatted compliance report string
-func GetRulesCompliance(clientset *kubernetes.Clientset, namespace string, appLabel string) string {
-	// Evaluate all rules
-	results := EvaluateRules(clientset, namespace, appLabel)
-	fmt.Printf("app-lable", appLabel)
-	// Get appropriate status symbols based on terminal capabilities
-	symbols := GetStatusSymbols()
+// ValidatePodNodeSpread checks that the running pods are not all placed on a single node.
+// Fewer than two running pods can't be spread and pass.
+func ValidatePodNodeSpread(pods []corev1.Pod) bool {
+	distribution := podNodeDistribution(pods)
+	running := 0
+	for _, count := range distribution {
+		running += count
+	}
+	return running < 2 || len(distribution) > 1
+}
 
-	// Format the results
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Compliance check for namespace: %s\n\n", namespace))
+// nodeZones maps node names to their topology.kubernetes.io/zone label
+func nodeZones(nodes []corev1.Node) map[string]string {
+	zones := make(map[string]string)
+	for _, node := range nodes {
+		if zone, exists := node.Labels[corev1.LabelTopologyZone]; exists {
+			zones[node.Name] = zone
+		}
+	}
+	return zones
+}
 
-	for _, result := range results {
-		symbol := symbols.Failure
-		if result.Passed {
-			symbol = symbols.Success
+// podZoneDistribution counts the running pods per availability zone of their node
+func podZoneDistribution(pods []corev1.Pod, nodes []corev1.Node) map[string]int {
+	zones := nodeZones(nodes)
+	distribution := make(map[string]int)
+	for node, count := range podNodeDistribution(pods) {
+		zone, exists := zones[node]
+		if !exists {
+			zone = "<no zone>"
 		}
+		distribution[zone] += count
+	}
+	return distribution
+}
 
-		sb.WriteString(fmt.Sprintf("%s %s: %s\n",
-			symbol,
-			result.Name,
-			result.Description))
+// ValidatePodZoneSpread checks that on a multi-zone cluster the running pods span more than one zone.
+// Single-zone clusters and fewer than two running pods pass.
+func ValidatePodZoneSpread(pods []corev1.Pod, nodes []corev1.Node) bool {
+	clusterZones := make(map[string]bool)
+	for _, zone := range nodeZones(nodes) {
+		clusterZones[zone] = true
+	}
+	if len(clusterZones) < 2 {
+		return true
 	}
 
-	return sb.String()
+	distribution := podZoneDistribution(pods, nodes)
+	running := 0
+	for _, count := range distribution {
+		running += count
+	}
+	return running < 2 || len(distribution) > 1
+}
+
+// rolloutGracePeriod is how long pods of an older pod-template-hash may remain after the newest
+// one appeared before the rollout counts as stuck
+const rolloutGracePeriod = 15 * time.Minute
+
+// isLivePod reports whether the pod still counts towards a rollout: it hasn't completed, failed or
+// started terminating. Such leftovers of an old ReplicaSet don't mean the rollout is stuck.
+func isLivePod(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp == nil && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// podTemplateHashes counts the live pods per pod-template-hash label, ignoring pods without one
+func podTemplateHashes(pods []corev1.Pod) map[string]int {
+	hashes := make(map[string]int)
+	for _, pod := range pods {
+		if !isLivePod(&pod) {
+			continue
+		}
+		if hash, exists := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; exists {
+			hashes[hash]++
+		}
+	}
+	return hashes
+}
+
+// newestTemplateHash returns the pod-template-hash that appeared last, i.e. whose first live pod is the
+// most recently created, and when that pod was created
+func newestTemplateHash(pods []corev1.Pod) (string, time.Time) {
+	firstSeen := make(map[string]time.Time)
+	for _, pod := range pods {
+		if !isLivePod(&pod) {
+			continue
+		}
+		hash, exists := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		if !exists {
+			continue
+		}
+		if seen, found := firstSeen[hash]; !found || pod.CreationTimestamp.Time.Before(seen) {
+			firstSeen[hash] = pod.CreationTimestamp.Time
+		}
+	}
+
+	var newest string
+	var since time.Time
+	for hash, seen := range firstSeen {
+		if newest == "" || seen.After(since) || (seen.Equal(since) && hash > newest) {
+			newest, since = hash, seen
+		}
+	}
+	return newest, since
+}
+
+// ValidateSingleTemplateHash checks the live pods don't keep running more than one pod-template-hash
+// beyond rolloutGracePeriod after the newest one appeared, which indicates a stuck rollout
+func ValidateSingleTemplateHash(pods []corev1.Pod, now time.Time) bool {
+	if len(podTemplateHashes(pods)) < 2 {
+		return true
+	}
+	_, since := newestTemplateHash(pods)
+	return now.Sub(since) <= rolloutGracePeriod
+}
+
+// stuckRolloutResult evaluates the Stuck Rollout rule at the given time; listed tells whether the pods could be listed
+func stuckRolloutResult(pods []corev1.Pod, listed bool, now time.Time) RuleResult {
+	description := fmt.Sprintf("Pods run a single pod-template-hash within %s of a rollout", rolloutGracePeriod)
+	if hashes := podTemplateHashes(pods); len(hashes) > 1 {
+		newest, since := newestTemplateHash(pods)
+		description += fmt.Sprintf(" (%s; newest %s since %s)", formatDistribution(hashes), newest,
+			now.Sub(since).Round(time.Second))
+	}
+	return RuleResult{
+		Name:        "Stuck Rollout",
+		Description: description,
+		Passed:      listed && ValidateSingleTemplateHash(pods, now),
+		Severity:    SeverityWarning,
+	}
+}
+
+// configReference is a ConfigMap or Secret the pod needs to start
+type configReference struct {
+	Kind string
+	Name string
+}
+
+// requiredConfigReferences collects the ConfigMaps and Secrets the pods reference through envFrom,
+// env valueFrom and volumes (including projected volumes), skipping references marked optional.
+// Each reference is listed once, however many pods share it.
+func requiredConfigReferences(pods []corev1.Pod) []configReference {
+	var refs []configReference
+	seen := make(map[configReference]bool)
+	for i := range pods {
+		refs = appendConfigReferences(refs, seen, &pods[i])
+	}
+	return refs
+}
+
+// appendConfigReferences appends the pod's required ConfigMaps and Secrets not seen yet to refs
+func appendConfigReferences(refs []configReference, seen map[configReference]bool, pod *corev1.Pod) []configReference {
+	add := func(kind, name string, optional *bool) {
+		ref := configReference{Kind: kind, Name: name}
+		if name == "" || (optional != nil && *optional) || seen[ref] {
+			return
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add("ConfigMap", envFrom.ConfigMapRef.Name, envFrom.ConfigMapRef.Optional)
+			}
+			if envFrom.SecretRef != nil {
+				add("Secret", envFrom.SecretRef.Name, envFrom.SecretRef.Optional)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				add("ConfigMap", ref.Name, ref.Optional)
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				add("Secret", ref.Name, ref.Optional)
+			}
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.ConfigMap != nil {
+			add("ConfigMap", volume.ConfigMap.Name, volume.ConfigMap.Optional)
+		}
+		if volume.Secret != nil {
+			add("Secret", volume.Secret.SecretName, volume.Secret.Optional)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add("ConfigMap", source.ConfigMap.Name, source.ConfigMap.Optional)
+				}
+				if source.Secret != nil {
+					add("Secret", source.Secret.Name, source.Secret.Optional)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// missingConfigReferences returns the required ConfigMaps and Secrets referenced by the pods
+// that don't exist in the namespace, as "Kind/name". Each one is looked up once.
+func missingConfigReferences(clientset kubernetes.Interface, namespace string, pods []corev1.Pod) ([]string, error) {
+	var missing []string
+	for _, ref := range requiredConfigReferences(pods) {
+		var err error
+		if ref.Kind == "ConfigMap" {
+			_, err = clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		} else {
+			_, err = clientset.CoreV1().Secrets(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		}
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("%s/%s", ref.Kind, ref.Name))
+		} else if err != nil {
+			return missing, fmt.Errorf("error retrieving %s %s: %v", ref.Kind, ref.Name, err)
+		}
+	}
+	return missing, nil
+}
+
+// ValidateReferencedConfigExists checks every required ConfigMap and Secret the pods reference exists.
+// A missing one leaves the pods stuck in ContainerCreating with a non-obvious error.
+func ValidateReferencedConfigExists(clientset kubernetes.Interface, namespace string, pods []corev1.Pod) bool {
+	missing, err := missingConfigReferences(clientset, namespace, pods)
+	return err == nil && len(missing) == 0
+}
+
+// unboundPVCs returns the claims that aren't Bound, with their phase
+func unboundPVCs(claims []corev1.PersistentVolumeClaim) []string {
+	var issues []string
+	for _, claim := range claims {
+		if claim.Status.Phase != corev1.ClaimBound {
+			issues = append(issues, fmt.Sprintf("%s (%s)", claim.Name, claim.Status.Phase))
+		}
+	}
+	return issues
+}
+
+// ValidatePVCsBound checks every PersistentVolumeClaim is Bound; a Pending claim leaves
+// its pods stuck in ContainerCreating
+func ValidatePVCsBound(claims []corev1.PersistentVolumeClaim) bool {
+	return len(unboundPVCs(claims)) == 0
+}
+
+// sidecarInjectionExpected reports whether Istio should inject a sidecar into the pod,
+// honouring a pod-level sidecar.istio.io/inject override over the namespace setting
+func sidecarInjectionExpected(pod *corev1.Pod, namespace *corev1.Namespace) bool {
+	inject, _ := sidecarInjectionDecision(pod, namespace)
+	return inject
+}
+
+// sidecarInjectionDecision returns whether Istio injects a sidecar into the pod and the
+// annotation, label or namespace setting deciding it
+func sidecarInjectionDecision(pod *corev1.Pod, namespace *corev1.Namespace) (bool, string) {
+	for _, source := range []struct {
+		kind   string
+		values map[string]string
+	}{{"pod annotation", pod.Annotations}, {"pod label", pod.Labels}} {
+		if inject, exists := source.values[istioInjectKey]; exists {
+			return inject == "true", fmt.Sprintf("%s %s=%s", source.kind, istioInjectKey, inject)
+		}
+	}
+
+	return namespaceInjectionDecision(namespace)
+}
+
+// istioInjectKey is the pod annotation (or label) overriding the namespace injection setting
+const istioInjectKey = "sidecar.istio.io/inject"
+
+// namespaceInjectionDecision returns whether the namespace labels turn on sidecar injection and the label deciding it
+func namespaceInjectionDecision(namespace *corev1.Namespace) (bool, string) {
+	if namespace == nil {
+		return false, "namespace unknown"
+	}
+	if value, exists := namespace.Labels["istio-injection"]; exists {
+		return value == "enabled", fmt.Sprintf("namespace label istio-injection=%s", value)
+	}
+	if revision, hasRevision := namespace.Labels["istio.io/rev"]; hasRevision {
+		return true, fmt.Sprintf("namespace label istio.io/rev=%s", revision)
+	}
+	return false, "no injection setting"
+}
+
+// istioPorts splits a comma-separated Istio port list annotation
+func istioPorts(value string) []string {
+	var ports []string
+	for _, port := range strings.Split(value, ",") {
+		if port = strings.TrimSpace(port); port != "" {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// istioPortLists pairs the traffic annotations that must not name the same port
+var istioPortLists = [][2]string{
+	{"traffic.sidecar.istio.io/includeInboundPorts", "traffic.sidecar.istio.io/excludeInboundPorts"},
+	{"traffic.sidecar.istio.io/includeOutboundPorts", "traffic.sidecar.istio.io/excludeOutboundPorts"},
+}
+
+// istioAnnotationIssues describes the Istio annotations of the pod that contradict each other,
+// the namespace injection setting or the effective injection decision
+func istioAnnotationIssues(pod *corev1.Pod, namespace *corev1.Namespace) []string {
+	var issues []string
+
+	annotationInject, hasAnnotation := pod.Annotations[istioInjectKey]
+	labelInject, hasLabel := pod.Labels[istioInjectKey]
+	for _, value := range []string{annotationInject, labelInject} {
+		if value != "" && value != "true" && value != "false" {
+			issues = append(issues, fmt.Sprintf("%s=%q is neither true nor false", istioInjectKey, value))
+		}
+	}
+	if hasAnnotation && hasLabel && annotationInject != labelInject {
+		issues = append(issues, fmt.Sprintf("%s label (%s) and annotation (%s) disagree", istioInjectKey, labelInject, annotationInject))
+	}
+
+	if namespace != nil {
+		if value, exists := namespace.Labels["istio-injection"]; exists {
+			if revision, hasRevision := namespace.Labels["istio.io/rev"]; hasRevision && value != "enabled" {
+				issues = append(issues, fmt.Sprintf("namespace sets istio.io/rev=%s but istio-injection=%s takes precedence", revision, value))
+			}
+		}
+	}
+
+	inject, reason := sidecarInjectionDecision(pod, namespace)
+	if namespaceInject, _ := namespaceInjectionDecision(namespace); !inject && namespaceInject {
+		issues = append(issues, fmt.Sprintf("%s overrides the namespace injection", reason))
+	}
+
+	if !inject {
+		var ineffective []string
+		for key := range pod.Annotations {
+			if strings.HasPrefix(key, "traffic.sidecar.istio.io/") || key == "proxy.istio.io/config" ||
+				(strings.HasPrefix(key, "sidecar.istio.io/") && key != istioInjectKey) {
+				ineffective = append(ineffective, key)
+			}
+		}
+		if len(ineffective) > 0 {
+			slices.Sort(ineffective)
+			issues = append(issues, fmt.Sprintf("%s have no effect without injection", strings.Join(ineffective, ", ")))
+		}
+	}
+
+	for _, pair := range istioPortLists {
+		included := make(map[string]bool)
+		for _, port := range istioPorts(pod.Annotations[pair[0]]) {
+			included[port] = true
+		}
+		for _, port := range istioPorts(pod.Annotations[pair[1]]) {
+			if included[port] {
+				issues = append(issues, fmt.Sprintf("port %s is both in %s and %s", port, pair[0], pair[1]))
+			}
+			if _, err := strconv.Atoi(port); err != nil {
+				issues = append(issues, fmt.Sprintf("%s lists an invalid port %q", pair[1], port))
+			}
+		}
+	}
+	return issues
+}
+
+// ValidateIstioAnnotations checks the pod's Istio annotations agree with each other and with the
+// namespace injection setting, e.g. no pod-level inject: false silently overriding namespace injection
+func ValidateIstioAnnotations(pod *corev1.Pod, namespace *corev1.Namespace) bool {
+	if pod == nil {
+		return false
+	}
+	return len(istioAnnotationIssues(pod, namespace)) == 0
+}
+
+// hasIstioProxy checks whether the pod runs the istio-proxy sidecar (as a container or native sidecar)
+func hasIstioProxy(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "istio-proxy" {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == "istio-proxy" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSidecarInjection checks that a pod expected to be injected actually has the istio-proxy sidecar
+func ValidateSidecarInjection(pod *corev1.Pod, namespace *corev1.Namespace) bool {
+	if pod == nil {
+		return false
+	}
+	return !sidecarInjectionExpected(pod, namespace) || hasIstioProxy(pod)
+}
+
+// ValidateDeploymentLabels checks if deployment has required labels
+func ValidateDeploymentLabels(deployment *appsv1.Deployment) bool {
+	if deployment == nil || len(deployment.Labels) == 0 {
+		return false
+	}
+
+	// Check for required labels (app and version are commonly required)
+	requiredLabels := []string{"app", "version"}
+	for _, label := range requiredLabels {
+		if _, exists := deployment.Labels[label]; !exists {
+			return false
+		}
+	}
+
+	return true
+}
+
+// missingDeploymentAnnotations returns the required annotations found neither on the
+// deployment nor on its pod template
+func missingDeploymentAnnotations(deployment *appsv1.Deployment, required []string) []string {
+	var missing []string
+	for _, annotation := range required {
+		_, onDeployment := deployment.Annotations[annotation]
+		_, onTemplate := deployment.Spec.Template.Annotations[annotation]
+		if !onDeployment && !onTemplate {
+			missing = append(missing, annotation)
+		}
+	}
+	return missing
+}
+
+// ValidateDeploymentAnnotations checks if deployment (or its pod template) has the required annotations
+func ValidateDeploymentAnnotations(deployment *appsv1.Deployment, required []string) bool {
+	if deployment == nil {
+		return false
+	}
+	return len(missingDeploymentAnnotations(deployment, required)) == 0
+}
+
+// defaultProgressDeadlineSeconds is the value Kubernetes applies when progressDeadlineSeconds is unset
+const defaultProgressDeadlineSeconds = 600
+
+// gitOpsMarker returns the first of the marker keys found among the deployment's labels or annotations, "" if none
+func gitOpsMarker(deployment *appsv1.Deployment, markers []string) string {
+	if deployment == nil {
+		return ""
+	}
+	for _, marker := range markers {
+		if _, exists := deployment.Labels[marker]; exists {
+			return marker
+		}
+		if _, exists := deployment.Annotations[marker]; exists {
+			return marker
+		}
+	}
+	return ""
+}
+
+// ValidateGitOpsOwnership checks the deployment carries one of the GitOps ownership markers,
+// i.e. it is managed by Argo CD or Flux rather than applied by hand
+func ValidateGitOpsOwnership(deployment *appsv1.Deployment, markers []string) bool {
+	return gitOpsMarker(deployment, markers) != ""
+}
+
+// ValidateProgressDeadline checks that the deployment sets a progressDeadlineSeconds other than
+// the Kubernetes default, no larger than maxSeconds, so stuck rollouts are reported as failed
+func ValidateProgressDeadline(deployment *appsv1.Deployment, maxSeconds int32) bool {
+	if deployment == nil || deployment.Spec.ProgressDeadlineSeconds == nil {
+		return false
+	}
+	deadline := *deployment.Spec.ProgressDeadlineSeconds
+	return deadline != defaultProgressDeadlineSeconds && deadline <= maxSeconds
+}
+
+// maxSafeUnavailableFraction is the largest share of the replicas a rolling update may take down at once
+const maxSafeUnavailableFraction = 1.0 / 3
+
+// defaultMaxUnavailable is the maxUnavailable Kubernetes applies when a rolling update doesn't set it
+var defaultMaxUnavailable = intstr.FromString("25%")
+
+// rollingUpdateUnavailable returns how many of the deployment's replicas a rolling update may take
+// down simultaneously, resolving a percentage maxUnavailable against the replica count (rounded
+// down, like the Deployment controller), along with the configured value and the replica count
+func rollingUpdateUnavailable(deployment *appsv1.Deployment) (int, string, int, error) {
+	replicas := 1
+	if deployment.Spec.Replicas != nil {
+		replicas = int(*deployment.Spec.Replicas)
+	}
+	maxUnavailable := defaultMaxUnavailable
+	if deployment.Spec.Strategy.RollingUpdate != nil && deployment.Spec.Strategy.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *deployment.Spec.Strategy.RollingUpdate.MaxUnavailable
+	}
+	unavailable, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, replicas, false)
+	if err != nil {
+		return 0, maxUnavailable.String(), replicas, err
+	}
+	return min(unavailable, replicas), maxUnavailable.String(), replicas, nil
+}
+
+// ValidateMinReplicas checks the deployment runs at least min replicas, spec.replicas counting as 1 when unset
+func ValidateMinReplicas(deployment *appsv1.Deployment, min int) bool {
+	return deployment != nil && int(specReplicas(deployment)) >= min
+}
+
+// ValidateMaxUnavailable checks a rolling update can't take down more than maxSafeUnavailableFraction
+// of the deployment's replicas at once. Recreate deployments stop every pod by design and are not checked.
+func ValidateMaxUnavailable(deployment *appsv1.Deployment) bool {
+	if deployment == nil {
+		return false
+	}
+	if deployment.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		return true
+	}
+	unavailable, _, replicas, err := rollingUpdateUnavailable(deployment)
+	return err == nil && float64(unavailable) <= maxSafeUnavailableFraction*float64(replicas)
+}
+
+// podAntiAffinityTerms returns the required and preferred podAntiAffinity terms of the deployment's pod template
+func podAntiAffinityTerms(deployment *appsv1.Deployment) []corev1.PodAffinityTerm {
+	affinity := deployment.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	terms := slices.Clone(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	for _, weighted := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		terms = append(terms, weighted.PodAffinityTerm)
+	}
+	return terms
+}
+
+// foreignAntiAffinitySelectors returns the podAntiAffinity label selectors that don't select the
+// deployment's own pods, e.g. copied from another app, or that look in other namespaces only
+func foreignAntiAffinitySelectors(deployment *appsv1.Deployment) []string {
+	var foreign []string
+	for _, term := range podAntiAffinityTerms(deployment) {
+		selectorText := metav1.FormatLabelSelector(term.LabelSelector)
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		switch {
+		case err != nil:
+			foreign = append(foreign, fmt.Sprintf("%s (invalid: %v)", selectorText, err))
+		case term.LabelSelector == nil || !selector.Matches(labels.Set(deployment.Spec.Template.Labels)):
+			foreign = append(foreign, selectorText)
+		case term.NamespaceSelector == nil && len(term.Namespaces) > 0 && !slices.Contains(term.Namespaces, deployment.Namespace):
+			foreign = append(foreign, fmt.Sprintf("%s in namespaces %s", selectorText, strings.Join(term.Namespaces, ", ")))
+		}
+	}
+	return foreign
+}
+
+// ValidateAntiAffinitySelector checks every podAntiAffinity term of the deployment selects its own
+// pods. Deployments without podAntiAffinity pass.
+func ValidateAntiAffinitySelector(deployment *appsv1.Deployment) bool {
+	if deployment == nil {
+		return false
+	}
+	return len(foreignAntiAffinitySelectors(deployment)) == 0
+}
+
+// specReplicas returns the replicas the deployment expects, spec.replicas or the default of 1
+func specReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas != nil {
+		return *deployment.Spec.Replicas
+	}
+	return 1
+}
+
+// readyReplicas returns the number of Ready pods and the replicas the deployment expects
+func readyReplicas(deployment *appsv1.Deployment, pods []corev1.Pod) (int, int) {
+	expected := int(specReplicas(deployment))
+	ready := 0
+	for i := range pods {
+		if k8s.IsPodReady(&pods[i]) {
+			ready++
+		}
+	}
+	return ready, expected
+}
+
+// ValidateReadyPercentage checks at least minPercent percent of the deployment's expected replicas have a
+// Ready pod. Running but unready pods don't count: 1 of 3 ready is degraded. Deployments scaled to zero pass.
+func ValidateReadyPercentage(deployment *appsv1.Deployment, pods []corev1.Pod, minPercent int) bool {
+	if deployment == nil {
+		return false
+	}
+	ready, expected := readyReplicas(deployment, pods)
+	return ready*100 >= expected*minPercent
+}
+
+// oomKills returns the containers of the pod last terminated for exceeding their memory limit
+func oomKills(pod *corev1.Pod) []string {
+	var killed []string
+	for _, termination := range k8s.GetPodTerminationReasons(pod) {
+		if termination.OOMKilled() {
+			killed = append(killed, termination.String())
+		}
+	}
+	return killed
+}
+
+// ValidateNoOOMKills checks no container of the pod was last terminated as OOMKilled
+func ValidateNoOOMKills(pod *corev1.Pod) bool {
+	return pod != nil && len(oomKills(pod)) == 0
+}
+
+// broadTolerations returns the pod's tolerations that tolerate more than intended taints: an empty key with
+// operator Exists, matching every taint (of the effect, when set), and the toleration of cordoned nodes
+func broadTolerations(pod *corev1.Pod) []string {
+	var broad []string
+	for _, toleration := range pod.Spec.Tolerations {
+		switch {
+		case toleration.Key == "" && toleration.Operator == corev1.TolerationOpExists && toleration.Effect == "":
+			broad = append(broad, "operator Exists without a key tolerates all taints")
+		case toleration.Key == "" && toleration.Operator == corev1.TolerationOpExists:
+			broad = append(broad, fmt.Sprintf("operator Exists without a key tolerates all %s taints", toleration.Effect))
+		case toleration.Key == corev1.TaintNodeUnschedulable:
+			broad = append(broad, fmt.Sprintf("%s lets pods onto cordoned nodes", corev1.TaintNodeUnschedulable))
+		}
+	}
+	return broad
+}
+
+// ValidateTolerations checks the pod doesn't tolerate every taint, which lets it schedule onto
+// dedicated (e.g. GPU) or maintenance nodes
+func ValidateTolerations(pod *corev1.Pod) bool {
+	return pod != nil && len(broadTolerations(pod)) == 0
+}
+
+// hpaReplicaConflicts returns the ways a deployment's static replica settings fight its HPA.
+// manifest tells the deployment was read from a rendered manifest (-manifests), see appliedReplicas.
+func hpaReplicaConflicts(deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler, manifest bool) []string {
+	var conflicts []string
+
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	if deployment.Spec.Replicas != nil {
+		replicas := *deployment.Spec.Replicas
+		if replicas < minReplicas || replicas > hpa.Spec.MaxReplicas {
+			conflicts = append(conflicts, fmt.Sprintf("replicas %d outside HPA range %d-%d",
+				replicas, minReplicas, hpa.Spec.MaxReplicas))
+		}
+	}
+
+	// A replica count in the applied manifest is reset by every kubectl/GitOps apply,
+	// undoing whatever the HPA scaled to
+	if applied := appliedReplicas(deployment, manifest); applied != nil {
+		conflicts = append(conflicts, fmt.Sprintf("applied manifest pins replicas to %d", *applied))
+	}
+
+	return conflicts
+}
+
+// appliedReplicas returns the replicas set by the manifest applied to the deployment, nil when it sets none.
+// A rendered manifest (manifest set) is what gets applied, so its spec.replicas counts. Otherwise they are read
+// from the last applied manifest of kubectl client-side apply (kubectl.kubernetes.io/last-applied-configuration),
+// else from spec.replicas when a field manager other than the HPA owns it, e.g. server-side apply by Flux or
+// Argo CD, or Helm.
+func appliedReplicas(deployment *appsv1.Deployment, manifest bool) *int32 {
+	if manifest {
+		return deployment.Spec.Replicas
+	}
+
+	if lastApplied, exists := deployment.Annotations["kubectl.kubernetes.io/last-applied-configuration"]; exists {
+		var applied struct {
+			Spec struct {
+				Replicas *int32 `json:"replicas"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil && applied.Spec.Replicas != nil {
+			return applied.Spec.Replicas
+		}
+	}
+
+	for _, entry := range deployment.ManagedFields {
+		// The HPA scales through the scale subresource, recorded as the kube-controller-manager's
+		if entry.Subresource == "scale" || entry.Manager == "kube-controller-manager" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Spec struct {
+				Replicas *json.RawMessage `json:"f:replicas"`
+			} `json:"f:spec"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err == nil && fields.Spec.Replicas != nil {
+			return deployment.Spec.Replicas
+		}
+	}
+	return nil
+}
+
+// ValidateHPAInitialReplicas checks that when the applied manifest pins replicas, which every deploy
+// resets the deployment to, they're at least half the HPA's minReplicas, so a deploy doesn't trigger a
+// scale-up storm, and at most its maxReplicas. spec.replicas on its own isn't used as the HPA rewrites
+// it, see appliedReplicas; a manifest that leaves replicas to the HPA passes. manifest tells the deployment was read from a rendered
+// manifest (-manifests), whose spec.replicas is what gets applied.
+func ValidateHPAInitialReplicas(deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler, manifest bool) bool {
+	if deployment == nil {
+		return false
+	}
+	if hpa == nil {
+		return true
+	}
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	applied := appliedReplicas(deployment, manifest)
+	if applied == nil {
+		return true
+	}
+	return *applied*2 >= minReplicas && *applied <= hpa.Spec.MaxReplicas
+}
+
+// ValidateStaticReplicas checks a deployment without an HPA runs at most maxReplicas replicas: a large
+// fixed count is sized for the peak, or was once, rather than for the actual load
+func ValidateStaticReplicas(deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler, maxReplicas int32) bool {
+	if deployment == nil {
+		return false
+	}
+	if hpa != nil {
+		return true
+	}
+	return specReplicas(deployment) <= maxReplicas
+}
+
+// ValidateHPAMetrics checks the HPA has a current value for each of its metrics and its ScalingActive
+// condition isn't false. Deployments without an HPA, and HPAs the controller hasn't synced yet, pass.
+func ValidateHPAMetrics(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	if hpa == nil || !k8s.HPAStatusReported(hpa) {
+		return true
+	}
+	scalingActive, _ := k8s.GetHPAScalingActive(hpa)
+	return scalingActive && len(k8s.GetHPAUnknownMetrics(hpa)) == 0
+}
+
+// ValidateHPAReplicaConflict checks that a deployment's replica settings don't conflict with its HPA.
+// manifest tells the deployment was read from a rendered manifest (-manifests), whose spec.replicas is applied.
+func ValidateHPAReplicaConflict(deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler, manifest bool) bool {
+	if deployment == nil {
+		return false
+	}
+	if hpa == nil {
+		return true
+	}
+	return len(hpaReplicaConflicts(deployment, hpa, manifest)) == 0
+}
+
+// ValidateServicePortNaming checks if service ports follow Istio naming conventions
+func ValidateServicePortNaming(service *corev1.Service) bool {
+	if debugLog != nil {
+		debugLog.Printf("Validating service ports for service: %s", service.Name)
+		debugLog.Printf("Service ports: %+v", service.Spec.Ports)
+	}
+	if service == nil || len(service.Spec.Ports) == 0 {
+		return false
+	}
+
+	validProtocols := []string{"http", "http2", "https", "tcp", "tls", "grpc", "mongo", "redis"}
+
+	for _, port := range service.Spec.Ports {
+		if port.Name == "" {
+			return false
+		}
+
+		// Split the port name by "-" and check if the first part is a valid protocol
+		portNameParts := strings.Split(strings.ToLower(port.Name), "-")
+		if len(portNameParts) == 0 {
+			return false
+		}
+
+		validProtocolFound := false
+		for _, protocol := range validProtocols {
+			if portNameParts[0] == protocol {
+				validProtocolFound = true
+				break
+			}
+		}
+
+		if !validProtocolFound {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateServiceHasScrapeTLS checks if the service has the label "scrape_tls = true"
+func ValidateServiceHasScrapeTLS(service *corev1.Service) bool {
+	if service == nil || service.Labels == nil {
+		return false
+	}
+	val, exists := service.Labels["scrape_tls"]
+	return exists && val == "true"
+}
+
+// scrapeTLSValue returns the scrape_tls label value for display, "<unset>" when absent
+func scrapeTLSValue(labels map[string]string) string {
+	if val, exists := labels["scrape_tls"]; exists {
+		return val
+	}
+	return "<unset>"
+}
+
+// scrapeTLSMismatches returns the deployment resources whose scrape_tls label differs from the service's
+func scrapeTLSMismatches(service *corev1.Service, deployment *appsv1.Deployment) []string {
+	expected := scrapeTLSValue(service.Labels)
+
+	var mismatches []string
+	if actual := scrapeTLSValue(deployment.Labels); actual != expected {
+		mismatches = append(mismatches, fmt.Sprintf("Deployment %s=%s", deployment.Name, actual))
+	}
+	if actual := scrapeTLSValue(deployment.Spec.Template.Labels); actual != expected {
+		mismatches = append(mismatches, fmt.Sprintf("pod template=%s", actual))
+	}
+	return mismatches
+}
+
+// ValidateScrapeTLSConsistency checks the scrape_tls label is the same on the service, deployment and pod template
+func ValidateScrapeTLSConsistency(service *corev1.Service, deployment *appsv1.Deployment) bool {
+	if service == nil || deployment == nil {
+		return false
+	}
+	return len(scrapeTLSMismatches(service, deployment)) == 0
+}
+
+// appLabelValue returns the app label value of the deployment's pods, or else the value of the app label
+// -label filters on, "" for another label
+func appLabelValue(deployment *appsv1.Deployment, appLabel string) string {
+	if deployment != nil && deployment.Spec.Template.Labels["app"] != "" {
+		return deployment.Spec.Template.Labels["app"]
+	}
+	if value, found := strings.CutPrefix(appLabel, "app="); found {
+		return strings.Trim(value, "\"")
+	}
+	return ""
+}
+
+// namingDivergences returns the Deployment name, Service name and app label value not following the naming
+// convention: not matching the pattern entirely when set, or else differing from the app label value (the
+// Deployment name without one). Missing resources are skipped.
+func namingDivergences(service *corev1.Service, deployment *appsv1.Deployment, appLabel string, pattern *regexp.Regexp) []string {
+	var names [][2]string
+	if deployment != nil {
+		names = append(names, [2]string{"Deployment", deployment.Name})
+	}
+	if service != nil {
+		names = append(names, [2]string{"Service", service.Name})
+	}
+	if value := appLabelValue(deployment, appLabel); value != "" {
+		names = append(names, [2]string{"app label", value})
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	var divergent []string
+	expected := names[len(names)-1][1]
+	for _, name := range names {
+		switch {
+		case pattern != nil && !pattern.MatchString(name[1]):
+			divergent = append(divergent, name[0]+" "+name[1])
+		case pattern == nil && name[1] != expected:
+			divergent = append(divergent, fmt.Sprintf("%s %s, expected %s", name[0], name[1], expected))
+		}
+	}
+	return divergent
+}
+
+// ValidateNamingConvention checks the Deployment name, Service name and app label value follow the naming
+// convention, so the resources of an app can be correlated by name. The deployment must exist.
+func ValidateNamingConvention(service *corev1.Service, deployment *appsv1.Deployment, appLabel string, pattern *regexp.Regexp) bool {
+	return deployment != nil && len(namingDivergences(service, deployment, appLabel, pattern)) == 0
+}
+
+// ValidateServicePortProtocols checks the protocol hints of each service port (name prefix, appProtocol,
+// transport protocol and the targeted container port's name) don't contradict each other
+func ValidateServicePortProtocols(service *corev1.Service, pods []corev1.Pod) bool {
+	return service != nil && len(k8s.PortProtocolConflicts(service, pods)) == 0
+}
+
+// ValidatePublishNotReadyAddresses checks the service only routes to ready pods: publishNotReadyAddresses
+// is allowed on headless services, where clustering apps use it for peer discovery
+func ValidatePublishNotReadyAddresses(service *corev1.Service) bool {
+	if service == nil {
+		return false
+	}
+	return !service.Spec.PublishNotReadyAddresses || service.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// cronJobPolicyIssues returns what a CronJob leaves to the permissive defaults: concurrencyPolicy Allow
+// (overlapping runs pile up) and no startingDeadlineSeconds (missed runs start arbitrarily late)
+func cronJobPolicyIssues(cronJob *batchv1.CronJob) []string {
+	var issues []string
+	if cronJob.Spec.ConcurrencyPolicy == "" || cronJob.Spec.ConcurrencyPolicy == batchv1.AllowConcurrent {
+		issues = append(issues, "concurrencyPolicy Allow")
+	}
+	if cronJob.Spec.StartingDeadlineSeconds == nil {
+		issues = append(issues, "no startingDeadlineSeconds")
+	}
+	return issues
+}
+
+// ValidateCronJobPolicies checks the CronJob sets a concurrencyPolicy other than Allow and a startingDeadlineSeconds
+func ValidateCronJobPolicies(cronJob *batchv1.CronJob) bool {
+	return cronJob != nil && len(cronJobPolicyIssues(cronJob)) == 0
+}
+
+// stickySessionsAnnotation declares, on the Service or Deployment, that the app needs sticky sessions
+const stickySessionsAnnotation = "k8s-rules-viewer/sticky-sessions"
+
+// requiresStickySessions reports whether the service or deployment is annotated (or labeled)
+// with sticky-sessions=true
+func requiresStickySessions(service *corev1.Service, deployment *appsv1.Deployment) bool {
+	var metas []metav1.ObjectMeta
+	if service != nil {
+		metas = append(metas, service.ObjectMeta)
+	}
+	if deployment != nil {
+		metas = append(metas, deployment.ObjectMeta)
+	}
+	for _, meta := range metas {
+		if meta.Annotations[stickySessionsAnnotation] == "true" || meta.Labels[stickySessionsAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionAffinityValue returns the service's sessionAffinity for display, "None" when unset
+func sessionAffinityValue(service *corev1.Service) string {
+	if service == nil || service.Spec.SessionAffinity == "" {
+		return string(corev1.ServiceAffinityNone)
+	}
+	return string(service.Spec.SessionAffinity)
+}
+
+// ValidateSessionAffinity checks the service pins clients to a pod with sessionAffinity: ClientIP
+func ValidateSessionAffinity(service *corev1.Service) bool {
+	return service != nil && service.Spec.SessionAffinity == corev1.ServiceAffinityClientIP
+}
+
+// EvaluateRules runs all validation rules against the resources in the namespace
+func EvaluateRules(clientset kubernetes.Interface, namespace string, appLabel string, opts RuleOptions) []RuleResult {
+	if debugLog != nil {
+		debugLog.Printf("Starting evaluation with appLabel: %q in namespace: %q", appLabel, namespace)
+	}
+
+	results := []RuleResult{}
+	ctx := context.TODO()
+
+	// Rule 1: Check if pods have serviceAccountName (for mTLS)
+	pods, err := k8s.GetPodsByLabel(clientset, namespace, appLabel)
+	if debugLog != nil {
+		debugLog.Printf("Pod list query result - Error: %v, Count: %d", err, len(pods))
+	}
+	podServiceAccountValid := false
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidatePodServiceAccount(&pod, appLabel) {
+				podServiceAccountValid = true
+				break
+			}
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "Service Account",
+		Description: "Pod serviceAccountName matches app label value",
+		Passed:      podServiceAccountValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check pods don't run as the default ServiceAccount, i.e. no dedicated one was created at all
+	dedicatedServiceAccountValid := false
+	defaultServiceAccountPod := ""
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidateNotDefaultServiceAccount(&pod) {
+				dedicatedServiceAccountValid = true
+				break
+			}
+			if defaultServiceAccountPod == "" {
+				defaultServiceAccountPod = fmt.Sprintf("%s runs as %s", pod.Name, podServiceAccountName(&pod))
+			}
+		}
+	}
+	dedicatedServiceAccountDescription := "Pods don't run as the default ServiceAccount"
+	if !dedicatedServiceAccountValid && defaultServiceAccountPod != "" {
+		dedicatedServiceAccountDescription += fmt.Sprintf(" (%s)", defaultServiceAccountPod)
+	}
+	results = append(results, RuleResult{
+		Name:        "Default Service Account",
+		Description: dedicatedServiceAccountDescription,
+		Passed:      dedicatedServiceAccountValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the app containers drop all Linux capabilities (hardening baseline)
+	capabilitiesValid := false
+	var capabilitiesNotDropped, capabilitiesAdded []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidateDroppedCapabilities(&pod) {
+				capabilitiesValid = true
+				_, capabilitiesAdded = capabilityIssues(&pod)
+				break
+			}
+			if capabilitiesNotDropped == nil {
+				capabilitiesNotDropped, capabilitiesAdded = capabilityIssues(&pod)
+			}
+		}
+	}
+	capabilitiesDescription := "App containers drop ALL capabilities"
+	var capabilitiesDetails []string
+	if !capabilitiesValid && len(capabilitiesNotDropped) > 0 {
+		capabilitiesDetails = append(capabilitiesDetails, "not dropping ALL: "+strings.Join(capabilitiesNotDropped, ", "))
+	}
+	capabilitiesDetails = append(capabilitiesDetails, capabilitiesAdded...)
+	if len(capabilitiesDetails) > 0 {
+		capabilitiesDescription += fmt.Sprintf(" (%s)", strings.Join(capabilitiesDetails, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Dropped Capabilities",
+		Description: capabilitiesDescription,
+		Passed:      capabilitiesValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the app containers have liveness and readiness probes, which rolling updates rely on
+	healthProbesValid := false
+	var probesMissing []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidatePodProbes(&pod) {
+				healthProbesValid = true
+				break
+			}
+			if probesMissing == nil {
+				probesMissing = missingProbes(&pod)
+			}
+		}
+	}
+	healthProbesDescription := "App containers define liveness and readiness probes"
+	if !healthProbesValid && len(probesMissing) > 0 {
+		healthProbesDescription += fmt.Sprintf(" (missing: %s)", strings.Join(probesMissing, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Health Probes",
+		Description: healthProbesDescription,
+		Passed:      healthProbesValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check that liveness probes don't fire before the app can start
+	probeTimingsValid := false
+	var probeTimingProblems []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidateProbeTimings(&pod) {
+				probeTimingsValid = true
+				break
+			}
+			if probeTimingProblems == nil {
+				probeTimingProblems = probeTimingIssues(&pod)
+			}
+		}
+	}
+	probeTimingsDescription := fmt.Sprintf("Liveness probes wait at least %ds or use a startupProbe", minLivenessInitialDelaySeconds)
+	if !probeTimingsValid && len(probeTimingProblems) > 0 {
+		probeTimingsDescription += fmt.Sprintf(" (too aggressive: %s)", strings.Join(probeTimingProblems, ", "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Probe Timings",
+		Description: probeTimingsDescription,
+		Passed:      probeTimingsValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule (opt-in): Check that liveness probes are not copies of aggressive readiness probes
+	if opts.ruleEnabled("Distinct Liveness Probe") {
+		distinctProbesValid := false
+		var sharedProbeProblems []string
+		for _, pod := range pods {
+			if ValidateDistinctProbes(&pod) {
+				distinctProbesValid = true
+				break
+			}
+			if sharedProbeProblems == nil {
+				sharedProbeProblems = sharedProbeIssues(&pod)
+			}
+		}
+		distinctProbesDescription := "Liveness probes are more lenient than (not identical to) readiness probes"
+		if !distinctProbesValid && len(sharedProbeProblems) > 0 {
+			distinctProbesDescription += fmt.Sprintf(" (identical probes: %s)", strings.Join(sharedProbeProblems, ", "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Distinct Liveness Probe",
+			Description: distinctProbesDescription,
+			Passed:      distinctProbesValid,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule (opt-in): Check that containers with a liveness probe have a startupProbe
+	if opts.ruleEnabled("Startup Probe") {
+		startupProbeValid := false
+		var startupProbeProblems []string
+		for _, pod := range pods {
+			if ValidateStartupProbe(&pod) {
+				startupProbeValid = true
+				break
+			}
+			if startupProbeProblems == nil {
+				startupProbeProblems = missingStartupProbes(&pod)
+			}
+		}
+		startupProbeDescription := "Containers with a liveness probe define a startupProbe"
+		if !startupProbeValid && len(startupProbeProblems) > 0 {
+			startupProbeDescription += fmt.Sprintf(" (missing in: %s)", strings.Join(startupProbeProblems, ", "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Startup Probe",
+			Description: startupProbeDescription,
+			Passed:      startupProbeValid,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule (opt-in): Check that httpGet probes target a declared container port
+	if opts.ruleEnabled("Probe Ports") {
+		probePortsValid := false
+		var probePortProblems []string
+		for _, pod := range pods {
+			if ValidateProbePorts(&pod) {
+				probePortsValid = true
+				break
+			}
+			if probePortProblems == nil {
+				probePortProblems = danglingProbePorts(&pod)
+			}
+		}
+		probePortsDescription := "Probe httpGet ports match a declared container port"
+		if !probePortsValid && len(probePortProblems) > 0 {
+			probePortsDescription += fmt.Sprintf(" (undeclared: %s)", strings.Join(probePortProblems, ", "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Probe Ports",
+			Description: probePortsDescription,
+			Passed:      probePortsValid,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule: Check the app containers set CPU and memory requests and limits
+	resourceLimitsValid := false
+	var resourcesMissing []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidatePodResourceLimits(&pod) {
+				resourceLimitsValid = true
+				break
+			}
+			if resourcesMissing == nil {
+				resourcesMissing = missingResources(&pod)
+			}
+		}
+	}
+	resourceLimitsDescription := "App containers set CPU and memory requests and limits"
+	if !resourceLimitsValid && len(resourcesMissing) > 0 {
+		resourceLimitsDescription += fmt.Sprintf(" (missing: %s)", strings.Join(resourcesMissing, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Resource Limits",
+		Description: resourceLimitsDescription,
+		Passed:      resourceLimitsValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule (opt-in): Check limits are not far larger than requests (capacity planning advice)
+	if opts.ruleEnabled("Resource Ratio") {
+		maxRatio := opts.MaxLimitRequestRatio
+		if maxRatio <= 0 {
+			maxRatio = DefaultMaxLimitRequestRatio
+		}
+		resourceRatioValid := false
+		var oversizedLimits, noBurst []string
+		for _, pod := range pods {
+			if ValidateResourceRatio(&pod, maxRatio) {
+				resourceRatioValid = true
+				_, noBurst = resourceRatioIssues(&pod, maxRatio)
+				break
+			}
+			if oversizedLimits == nil {
+				oversizedLimits, noBurst = resourceRatioIssues(&pod, maxRatio)
+			}
+		}
+		resourceRatioDescription := fmt.Sprintf("Container CPU/memory limits are at most %gx the requests", maxRatio)
+		var resourceRatioDetails []string
+		if !resourceRatioValid {
+			resourceRatioDetails = append(resourceRatioDetails, oversizedLimits...)
+		}
+		if len(noBurst) > 0 {
+			resourceRatioDetails = append(resourceRatioDetails, "CPU limit equals request, no burst: "+strings.Join(noBurst, ", "))
+		}
+		if len(resourceRatioDetails) > 0 {
+			resourceRatioDescription += fmt.Sprintf(" (%s)", strings.Join(resourceRatioDetails, "; "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Resource Ratio",
+			Description: resourceRatioDescription,
+			Passed:      resourceRatioValid,
+			Severity:    SeverityInfo,
+		})
+	}
+
+	// Rule: Check the images are immutable, so every pod of a version runs the same code
+	imageTagsValid := false
+	var mutableImageRefs []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidateImageTags(&pod) {
+				imageTagsValid = true
+				break
+			}
+			if mutableImageRefs == nil {
+				mutableImageRefs = mutableImages(&pod)
+			}
+		}
+	}
+	imageTagsDescription := "Container images are pinned by digest or an immutable tag, not latest or a branch name"
+	if !imageTagsValid && len(mutableImageRefs) > 0 {
+		imageTagsDescription += fmt.Sprintf(" (%s)", strings.Join(mutableImageRefs, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Image Tag Policy",
+		Description: imageTagsDescription,
+		Passed:      imageTagsValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule (opt-in): Check pods pulling from private registries have a pull secret
+	if opts.ruleEnabled("Image Pull Secrets") {
+		publicRegistries := opts.PublicRegistries
+		if len(publicRegistries) == 0 {
+			publicRegistries = DefaultPublicRegistries
+		}
+		pullSecretsValid := false
+		var unauthenticatedImages []string
+		serviceAccounts := make(map[string]*corev1.ServiceAccount)
+		for _, pod := range pods {
+			name := pod.Spec.ServiceAccountName
+			if name == "" {
+				name = "default"
+			}
+			serviceAccount, fetched := serviceAccounts[name]
+			if !fetched {
+				var saErr error
+				serviceAccount, saErr = clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+				if saErr != nil {
+					if debugLog != nil {
+						debugLog.Printf("ServiceAccount query for %s failed: %v", name, saErr)
+					}
+					serviceAccount = nil
+				}
+				serviceAccounts[name] = serviceAccount
+			}
+			if ValidateImagePullSecrets(&pod, serviceAccount, publicRegistries) {
+				pullSecretsValid = true
+				break
+			}
+			if unauthenticatedImages == nil {
+				unauthenticatedImages = privateRegistryImages(&pod, publicRegistries)
+			}
+		}
+		pullSecretsDescription := "Pods pulling from private registries have imagePullSecrets (on the pod or its ServiceAccount)"
+		if !pullSecretsValid && len(unauthenticatedImages) > 0 {
+			pullSecretsDescription += fmt.Sprintf(" (no pull secret for: %s)", strings.Join(unauthenticatedImages, ", "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Image Pull Secrets",
+			Description: pullSecretsDescription,
+			Passed:      pullSecretsValid,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule (opt-in): Check the app containers name their ports
+	if opts.ruleEnabled("Container Port Names") {
+		portNamesValid := false
+		var unnamedPorts []string
+		for _, pod := range pods {
+			if ValidateContainerPortNames(&pod) {
+				portNamesValid = true
+				break
+			}
+			if unnamedPorts == nil {
+				unnamedPorts = unnamedContainerPorts(&pod)
+			}
+		}
+		portNamesDescription := "App container ports have names"
+		if !portNamesValid && len(unnamedPorts) > 0 {
+			portNamesDescription += fmt.Sprintf(" (unnamed: %s)", strings.Join(unnamedPorts, "; "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Container Port Names",
+			Description: portNamesDescription,
+			Passed:      portNamesValid,
+			Severity:    SeverityInfo,
+		})
+	}
+
+	// Rule: Check a rollout doesn't leave pods of several pod-template-hashes behind
+	results = append(results, stuckRolloutResult(pods, err == nil, time.Now()))
+
+	// Rule: Check the running pods are actually spread over more than one node
+	nodeDistribution := podNodeDistribution(pods)
+	nodeSpreadDescription := "Running pods are spread over more than one node"
+	if len(nodeDistribution) > 0 {
+		nodeSpreadDescription += fmt.Sprintf(" (%s)", formatDistribution(nodeDistribution))
+	}
+	results = append(results, RuleResult{
+		Name:        "Node Spread",
+		Description: nodeSpreadDescription,
+		Passed:      err == nil && ValidatePodNodeSpread(pods),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check the running pods span more than one availability zone
+	nodes, nodesErr := k8s.GetNodes(clientset)
+	if nodesErr != nil && debugLog != nil {
+		debugLog.Printf("Node list query failed: %v", nodesErr)
+	}
+	zoneSpreadDescription := "Running pods span more than one availability zone (multi-zone clusters)"
+	if zoneDistribution := podZoneDistribution(pods, nodes); nodesErr == nil && len(zoneDistribution) > 0 {
+		zoneSpreadDescription += fmt.Sprintf(" (%s)", formatDistribution(zoneDistribution))
+	}
+	results = append(results, RuleResult{
+		Name:        "Zone Spread",
+		Description: zoneSpreadDescription,
+		Passed:      err == nil && nodesErr == nil && ValidatePodZoneSpread(pods, nodes),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check that pods expecting Istio injection actually have the sidecar
+	namespaceObj, nsErr := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if nsErr != nil {
+		if debugLog != nil {
+			debugLog.Printf("Namespace query for %s failed: %v", namespace, nsErr)
+		}
+		namespaceObj = nil
+	}
+	var podsMissingSidecar []string
+	for _, pod := range pods {
+		if !ValidateSidecarInjection(&pod, namespaceObj) {
+			podsMissingSidecar = append(podsMissingSidecar, pod.Name)
+		}
+	}
+	sidecarDescription := "Pods expecting Istio injection have the istio-proxy sidecar"
+	if len(podsMissingSidecar) > 0 {
+		sidecarDescription += fmt.Sprintf(" (missing in: %s)", strings.Join(podsMissingSidecar, ", "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Sidecar Injection",
+		Description: sidecarDescription,
+		Passed:      err == nil && len(podsMissingSidecar) == 0,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the Istio annotations agree with each other and the namespace injection setting
+	istioAnnotationsValid := err == nil && len(pods) > 0
+	istioAnnotationsDescription := "Istio annotations are consistent with each other and the namespace injection"
+	for i, pod := range pods {
+		inject, reason := sidecarInjectionDecision(&pod, namespaceObj)
+		if i == 0 {
+			istioAnnotationsDescription += fmt.Sprintf(" (effective injection: %t, from %s)", inject, reason)
+		}
+		if !ValidateIstioAnnotations(&pod, namespaceObj) {
+			istioAnnotationsValid = false
+			istioAnnotationsDescription += fmt.Sprintf(" (pod %s: %s)", pod.Name, strings.Join(istioAnnotationIssues(&pod, namespaceObj), "; "))
+			break
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "Istio Annotations",
+		Description: istioAnnotationsDescription,
+		Passed:      istioAnnotationsValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check that every ConfigMap and Secret the pods reference exists
+	missingConfigs, configLookupErr := missingConfigReferences(clientset, namespace, pods)
+	if configLookupErr != nil && debugLog != nil {
+		debugLog.Printf("Referenced config lookup failed: %v", configLookupErr)
+	}
+	referencedConfigDescription := "ConfigMaps and Secrets referenced by the pods exist"
+	if configLookupErr != nil {
+		referencedConfigDescription += fmt.Sprintf(" (%v)", configLookupErr)
+	} else if len(missingConfigs) > 0 {
+		referencedConfigDescription += fmt.Sprintf(" (missing: %s)", strings.Join(missingConfigs, ", "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Referenced Config",
+		Description: referencedConfigDescription,
+		Passed:      err == nil && configLookupErr == nil && len(missingConfigs) == 0,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the PersistentVolumeClaims mounted by the pods are Bound
+	claims, missingClaims, claimErr := k8s.GetPVCsForPods(clientset, namespace, pods)
+	if claimErr != nil && debugLog != nil {
+		debugLog.Printf("PVC lookup failed: %v", claimErr)
+	}
+	pvcProblems := unboundPVCs(claims)
+	for _, name := range missingClaims {
+		pvcProblems = append(pvcProblems, fmt.Sprintf("%s (not found)", name))
+	}
+	pvcDescription := "PersistentVolumeClaims used by the pods are Bound"
+	if claimErr != nil {
+		pvcDescription += fmt.Sprintf(" (%v)", claimErr)
+	} else if len(claims) == 0 && len(missingClaims) == 0 {
+		pvcDescription += " (no PVCs)"
+	} else if len(pvcProblems) > 0 {
+		pvcDescription += fmt.Sprintf(" (not bound: %s)", strings.Join(pvcProblems, ", "))
+	}
+	results = append(results, RuleResult{
+		Name:        "PVC Bound",
+		Description: pvcDescription,
+		Passed:      err == nil && claimErr == nil && len(missingClaims) == 0 && ValidatePVCsBound(claims),
+		Severity:    SeverityCritical,
+	})
+
+	// Rule 2: Check if deployments have required labels
+	deployments, err := k8s.GetDeploymentsByLabel(clientset, namespace, appLabel)
+	if debugLog != nil {
+		debugLog.Printf("Deployment list query result - Error: %v, Count: %d", err, len(deployments))
+	}
+	deploymentLabelsValid := false
+	if err == nil && len(deployments) > 0 {
+		for _, deployment := range deployments {
+			if ValidateDeploymentLabels(&deployment) {
+				deploymentLabelsValid = true
+				break
+			}
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "Deployment Labels",
+		Description: "Deployment has required labels (app, version)",
+		Passed:      deploymentLabelsValid,
+		Severity:    SeverityWarning,
+	})
+
+	var deployment *appsv1.Deployment
+	if len(deployments) > 0 {
+		deployment = &deployments[0]
+	}
+
+	// Rule: Check that the deployment carries the annotations required by policy
+	if len(opts.RequiredAnnotations) > 0 {
+		annotationsDescription := fmt.Sprintf("Deployment has required annotations (%s)", strings.Join(opts.RequiredAnnotations, ", "))
+		annotationsValid := ValidateDeploymentAnnotations(deployment, opts.RequiredAnnotations)
+		if deployment != nil && !annotationsValid {
+			annotationsDescription += fmt.Sprintf(" (missing: %s)",
+				strings.Join(missingDeploymentAnnotations(deployment, opts.RequiredAnnotations), ", "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Deployment Annotations",
+			Description: annotationsDescription,
+			Passed:      annotationsValid,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule (opt-in): Check that the deployment is managed by a GitOps tool, not applied by hand
+	if opts.ruleEnabled("GitOps Ownership") {
+		markers := opts.GitOpsMarkers
+		if len(markers) == 0 {
+			markers = DefaultGitOpsMarkers
+		}
+		gitOpsDescription := fmt.Sprintf("Deployment carries a GitOps ownership label or annotation (%s)", strings.Join(markers, ", "))
+		if marker := gitOpsMarker(deployment, markers); marker != "" {
+			gitOpsDescription += fmt.Sprintf(" (found: %s)", marker)
+		}
+		results = append(results, RuleResult{
+			Name:        "GitOps Ownership",
+			Description: gitOpsDescription,
+			Passed:      ValidateGitOpsOwnership(deployment, markers),
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule: Check that stuck rollouts are detected in a reasonable time
+	progressDeadlineDescription := fmt.Sprintf("Deployment sets a non-default progressDeadlineSeconds of at most %ds",
+		opts.MaxProgressDeadlineSeconds)
+	if deployment != nil {
+		if deployment.Spec.ProgressDeadlineSeconds != nil {
+			progressDeadlineDescription += fmt.Sprintf(" (current: %ds)", *deployment.Spec.ProgressDeadlineSeconds)
+		} else {
+			progressDeadlineDescription += " (current: unset)"
+		}
+	}
+	progressDeadlineValid := ValidateProgressDeadline(deployment, opts.MaxProgressDeadlineSeconds)
+	progressDeadlineResult := RuleResult{
+		Name:        "Progress Deadline",
+		Description: progressDeadlineDescription,
+		Passed:      progressDeadlineValid,
+		Severity:    SeverityWarning,
+	}
+	if !progressDeadlineValid {
+		progressDeadlineResult.FixCommand = progressDeadlineFixCommand(deployment, namespace, opts.MaxProgressDeadlineSeconds)
+	}
+	results = append(results, progressDeadlineResult)
+
+	// Rule: Check the deployment runs enough replicas to survive losing a pod or a node
+	minReplicas := opts.MinReplicas
+	if minReplicas == 0 {
+		minReplicas = DefaultMinReplicas
+	}
+	replicaCountDescription := fmt.Sprintf("Deployment runs at least %d replicas", minReplicas)
+	if deployment != nil {
+		replicaCountDescription += fmt.Sprintf(" (%d replicas, minimum %d)", specReplicas(deployment), minReplicas)
+	}
+	results = append(results, RuleResult{
+		Name:        "Replica Count",
+		Description: replicaCountDescription,
+		Passed:      ValidateMinReplicas(deployment, minReplicas),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check a rolling update can't take down too large a share of the replicas at once
+	maxUnavailableDescription := "Rolling updates take down at most a third of the replicas at once"
+	if deployment != nil && deployment.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		maxUnavailableDescription += " (Recreate strategy)"
+	} else if deployment != nil {
+		unavailable, maxUnavailable, replicas, unavailableErr := rollingUpdateUnavailable(deployment)
+		if unavailableErr != nil {
+			maxUnavailableDescription += fmt.Sprintf(" (invalid maxUnavailable %q: %v)", maxUnavailable, unavailableErr)
+		} else {
+			maxUnavailableDescription += fmt.Sprintf(" (maxUnavailable %s of %d replicas: %d down at once)",
+				maxUnavailable, replicas, unavailable)
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "Max Unavailable",
+		Description: maxUnavailableDescription,
+		Passed:      ValidateMaxUnavailable(deployment),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check the podAntiAffinity spreads the app's own pods, not another app's
+	antiAffinityDescription := "podAntiAffinity label selectors match the Deployment's own pod labels"
+	if deployment != nil {
+		if len(podAntiAffinityTerms(deployment)) == 0 {
+			antiAffinityDescription += " (no podAntiAffinity)"
+		} else if foreign := foreignAntiAffinitySelectors(deployment); len(foreign) > 0 {
+			antiAffinityDescription += fmt.Sprintf(" (pod labels %s not selected by: %s)",
+				labels.Set(deployment.Spec.Template.Labels), strings.Join(foreign, "; "))
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "Anti-Affinity Selector",
+		Description: antiAffinityDescription,
+		Passed:      ValidateAntiAffinitySelector(deployment),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check enough of the expected replicas are Ready, not merely running. Manifests have no pods running.
+	if !opts.Manifests {
+		minReadyPercent := opts.MinReadyPercent
+		if minReadyPercent == 0 {
+			minReadyPercent = DefaultMinReadyPercent
+		}
+		readyReplicasDescription := fmt.Sprintf("At least %d%% of the Deployment's replicas are Ready", minReadyPercent)
+		if deployment != nil && err == nil {
+			ready, expected := readyReplicas(deployment, pods)
+			if expected > 0 {
+				readyReplicasDescription += fmt.Sprintf(" (%d/%d ready, %d%%)", ready, expected, ready*100/expected)
+			} else {
+				readyReplicasDescription += " (scaled to zero)"
+			}
+		}
+		results = append(results, RuleResult{
+			Name:        "Ready Replicas",
+			Description: readyReplicasDescription,
+			Passed:      err == nil && ValidateReadyPercentage(deployment, pods, minReadyPercent),
+			Severity:    SeverityCritical,
+		})
+	}
+
+	// Rule: Check no container was killed for running out of memory; unlike most pod rules a single
+	// pod fails it, as each OOMKill is a crash
+	oomKillsValid := err == nil && len(pods) > 0
+	var oomKilled []string
+	for _, pod := range pods {
+		if !ValidateNoOOMKills(&pod) {
+			oomKillsValid = false
+			for _, kill := range oomKills(&pod) {
+				oomKilled = append(oomKilled, pod.Name+"/"+kill)
+			}
+		}
+	}
+	oomKillsDescription := "No container was last terminated as OOMKilled"
+	if len(oomKilled) > 0 {
+		oomKillsDescription += fmt.Sprintf(" (%s)", strings.Join(oomKilled, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "OOMKilled",
+		Description: oomKillsDescription,
+		Passed:      oomKillsValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the pods only tolerate the taints they're meant to, not blanket ones
+	tolerationsValid := false
+	var tolerationProblems []string
+	if err == nil && len(pods) > 0 {
+		for _, pod := range pods {
+			if ValidateTolerations(&pod) {
+				tolerationsValid = true
+				break
+			}
+			if tolerationProblems == nil {
+				tolerationProblems = broadTolerations(&pod)
+			}
+		}
+	}
+	tolerationsDescription := "Pods don't tolerate all taints"
+	if !tolerationsValid && len(tolerationProblems) > 0 {
+		tolerationsDescription += fmt.Sprintf(" (%s)", strings.Join(tolerationProblems, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Tolerations",
+		Description: tolerationsDescription,
+		Passed:      tolerationsValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check that the deployment's replica settings don't fight its HPA
+	hpaConflictValid := false
+	hpaDescription := "Deployment replicas don't conflict with its HorizontalPodAutoscaler"
+	var hpa *autoscalingv2.HorizontalPodAutoscaler
+	var hpaErr error
+	if deployment != nil {
+		hpa, hpaErr = k8s.GetHPAForDeployment(clientset, namespace, deployment.Name)
+		if debugLog != nil {
+			debugLog.Printf("HPA query for deployment %s - Error: %v, Found: %t", deployment.Name, hpaErr, hpa != nil)
+		}
+		if hpaErr == nil {
+			hpaConflictValid = ValidateHPAReplicaConflict(deployment, hpa, opts.Manifests)
+			if hpa == nil {
+				hpaDescription += " (no HPA)"
+			} else if conflicts := hpaReplicaConflicts(deployment, hpa, opts.Manifests); len(conflicts) > 0 {
+				hpaDescription += fmt.Sprintf(" (%s: %s)", hpa.Name, strings.Join(conflicts, "; "))
+			}
+		}
+	}
+	results = append(results, RuleResult{
+		Name:        "HPA Replica Conflict",
+		Description: hpaDescription,
+		Passed:      hpaConflictValid,
+		Severity:    SeverityWarning,
+	})
+
+	// Rule: Check a deploy resetting the replicas starts close enough to the HPA's range not to cause a scale storm
+	if deployment != nil && hpa != nil {
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		initialDescription := "applied manifest leaves replicas to the HPA"
+		if applied := appliedReplicas(deployment, opts.Manifests); applied != nil {
+			initialDescription = fmt.Sprintf("applied manifest sets %d replicas", *applied)
+		}
+		results = append(results, RuleResult{
+			Name: "HPA Initial Replicas",
+			Description: fmt.Sprintf("Deploys start with at least half the HPA minReplicas and at most its maxReplicas "+
+				"(%s, HPA %s range %d-%d)", initialDescription, hpa.Name, minReplicas, hpa.Spec.MaxReplicas),
+			Passed:   ValidateHPAInitialReplicas(deployment, hpa, opts.Manifests),
+			Severity: SeverityWarning,
+		})
+	}
+
+	// Rule: Check the HPA gets its metrics, without which it silently can't scale. It needs the status
+	// reported by the HPA controller, which manifests and a just-created HPA don't have.
+	if hpa != nil && !opts.Manifests && k8s.HPAStatusReported(hpa) {
+		unknownMetrics := k8s.GetHPAUnknownMetrics(hpa)
+		scalingActive, inactiveReason := k8s.GetHPAScalingActive(hpa)
+		var hpaMetricsProblems []string
+		if len(unknownMetrics) > 0 {
+			hpaMetricsProblems = append(hpaMetricsProblems, "unknown metrics: "+strings.Join(unknownMetrics, ", "))
+		}
+		if !scalingActive {
+			hpaMetricsProblems = append(hpaMetricsProblems, "ScalingActive False, "+inactiveReason)
+		}
+		hpaMetricsDescription := fmt.Sprintf("HPA %s has current values for its metrics and ScalingActive", hpa.Name)
+		if len(hpaMetricsProblems) > 0 {
+			hpaMetricsDescription += fmt.Sprintf(" (%s)", strings.Join(hpaMetricsProblems, "; "))
+		}
+		results = append(results, RuleResult{
+			Name:        "HPA Metrics",
+			Description: hpaMetricsDescription,
+			Passed:      ValidateHPAMetrics(hpa),
+			Severity:    SeverityCritical,
+		})
+	}
+
+	// Rule (opt-in): Check a large replica count follows the load through an HPA rather than being fixed
+	if opts.ruleEnabled("Static Replicas") {
+		maxStaticReplicas := opts.MaxStaticReplicas
+		if maxStaticReplicas == 0 {
+			maxStaticReplicas = DefaultMaxStaticReplicas
+		}
+		staticReplicasDescription := fmt.Sprintf("Deployments with more than %d replicas are autoscaled by an HPA", maxStaticReplicas)
+		switch {
+		case hpaErr != nil:
+			staticReplicasDescription += fmt.Sprintf(" (%v)", hpaErr)
+		case hpa != nil:
+			staticReplicasDescription += fmt.Sprintf(" (HPA %s)", hpa.Name)
+		case deployment != nil:
+			staticReplicasDescription += fmt.Sprintf(" (%d static replicas, no HPA)", specReplicas(deployment))
+		}
+		results = append(results, RuleResult{
+			Name:        "Static Replicas",
+			Description: staticReplicasDescription,
+			Passed:      hpaErr == nil && ValidateStaticReplicas(deployment, hpa, maxStaticReplicas),
+			Severity:    SeverityInfo,
+		})
+	}
+
+	servicePortsValid := false
+	serviceScrapeTLSValid := false
+	var service *corev1.Service
+	if appLabel != "" {
+		for _, selector := range serviceLabelSelectors(appLabel) {
+			if debugLog != nil {
+				debugLog.Printf("Trying service label selector: %s", selector)
+			}
+
+			services, err := k8s.GetServicesByLabel(clientset, namespace, selector)
+			if debugLog != nil {
+				debugLog.Printf("Service list query result for %s - Error: %v, Count: %d",
+					selector, err, len(services))
+			}
+
+			if err == nil && len(services) > 0 {
+				service = &services[0]
+				if debugLog != nil {
+					debugLog.Printf("Found service: %s with labels: %v", service.Name, service.Labels)
+				}
+				break
+			}
+		}
+
+		if service != nil {
+			servicePortsValid = ValidateServicePortNaming(service)
+			serviceScrapeTLSValid = ValidateServiceHasScrapeTLS(service)
+		}
+	}
+
+	results = append(results, RuleResult{
+		Name:        "Service Port Naming",
+		Description: fmt.Sprintf("Service (%s) ports follow Istio naming conventions", appLabel),
+		Passed:      servicePortsValid,
+		Severity:    SeverityCritical,
+	})
+
+	// Rule: Check the protocol a service port declares is the same everywhere it's hinted at
+	portProtocolsDescription := "Service port names, appProtocol and container ports declare the same protocol"
+	if conflicts := k8s.PortProtocolConflicts(service, pods); len(conflicts) > 0 {
+		portProtocolsDescription += fmt.Sprintf(" (%s)", strings.Join(conflicts, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Port Protocol Consistency",
+		Description: portProtocolsDescription,
+		Passed:      ValidateServicePortProtocols(service, pods),
+		Severity:    SeverityWarning,
+	})
+
+	// Rule (opt-in): Check the Deployment, Service and app label are named consistently
+	if opts.ruleEnabled("Naming Convention") {
+		namingDescription := "Deployment name, Service name and app label value are equal"
+		var namingPattern *regexp.Regexp
+		var patternErr error
+		if opts.NamingPattern != "" {
+			namingDescription = fmt.Sprintf("Deployment name, Service name and app label value match %s", opts.NamingPattern)
+			namingPattern, patternErr = regexp.Compile("^(?:" + opts.NamingPattern + ")$")
+		}
+		if patternErr != nil {
+			namingDescription += fmt.Sprintf(" (invalid pattern: %v)", patternErr)
+		} else if divergent := namingDivergences(service, deployment, appLabel, namingPattern); len(divergent) > 0 {
+			namingDescription += fmt.Sprintf(" (%s)", strings.Join(divergent, "; "))
+		}
+		results = append(results, RuleResult{
+			Name:        "Naming Convention",
+			Description: namingDescription,
+			Passed:      patternErr == nil && ValidateNamingConvention(service, deployment, appLabel, namingPattern),
+			Severity:    SeverityInfo,
+		})
+	}
+
+	scrapeTLSLabelResult := RuleResult{
+		Name:        "Service scrape_tls Label",
+		Description: fmt.Sprintf("Service (%s) has label scrape_tls = true", appLabel),
+		Passed:      serviceScrapeTLSValid,
+		Severity:    SeverityWarning,
+	}
+	if !serviceScrapeTLSValid {
+		scrapeTLSLabelResult.FixCommand = scrapeTLSLabelFixCommand(service, namespace)
+	}
+	results = append(results, scrapeTLSLabelResult)
+
+	// Rule: Check the service doesn't route to pods before they are ready
+	publishNotReadyDescription := "Service only routes to ready pods (publishNotReadyAddresses unset unless headless)"
+	if service != nil && service.Spec.PublishNotReadyAddresses && !ValidatePublishNotReadyAddresses(service) {
+		publishNotReadyDescription += fmt.Sprintf(" (Service %s sets publishNotReadyAddresses: true)", service.Name)
+	}
+	publishNotReadyValid := ValidatePublishNotReadyAddresses(service)
+	publishNotReadyResult := RuleResult{
+		Name:        "Ready Endpoints Only",
+		Description: publishNotReadyDescription,
+		Passed:      publishNotReadyValid,
+		Severity:    SeverityCritical,
+	}
+	if !publishNotReadyValid {
+		publishNotReadyResult.FixCommand = publishNotReadyFixCommand(service, namespace)
+	}
+	results = append(results, publishNotReadyResult)
+
+	// Rule: Check that the scrape_tls label agrees between the service and the deployment
+	scrapeTLSConsistent := false
+	scrapeTLSDescription := "scrape_tls label matches across Service, Deployment and pod template"
+	if service != nil && deployment != nil {
+		scrapeTLSConsistent = ValidateScrapeTLSConsistency(service, deployment)
+		if mismatches := scrapeTLSMismatches(service, deployment); len(mismatches) > 0 {
+			scrapeTLSDescription += fmt.Sprintf(" (Service %s has %s, differs on: %s)",
+				service.Name, scrapeTLSValue(service.Labels), strings.Join(mismatches, ", "))
+		}
+	}
+	scrapeTLSConsistencyResult := RuleResult{
+		Name:        "scrape_tls Consistency",
+		Description: scrapeTLSDescription,
+		Passed:      scrapeTLSConsistent,
+		Severity:    SeverityWarning,
+	}
+	if !scrapeTLSConsistent {
+		scrapeTLSConsistencyResult.FixCommand = scrapeTLSConsistencyFixCommand(service, deployment, namespace)
+	}
+	results = append(results, scrapeTLSConsistencyResult)
+
+	// Rule: Check a Service labeled for scraping is selected by a ServiceMonitor, when the
+	// Prometheus Operator CRD is installed
+	if ValidateServiceHasScrapeTLS(service) && opts.DynamicClient != nil {
+		installed, discoveryErr := k8s.ServiceMonitorsInstalled(clientset.Discovery())
+		if discoveryErr != nil && debugLog != nil {
+			debugLog.Printf("ServiceMonitor check skipped: %v", discoveryErr)
+		}
+		if installed {
+			monitors, monitorErr := k8s.GetServiceMonitorsForService(opts.DynamicClient, service)
+			serviceMonitorDescription := fmt.Sprintf("A ServiceMonitor selects the Service (%s)", service.Name)
+			switch {
+			case monitorErr != nil:
+				serviceMonitorDescription += fmt.Sprintf(" (%v)", monitorErr)
+			case len(monitors) == 0:
+				serviceMonitorDescription += " (none found, the Service is not scraped)"
+			default:
+				serviceMonitorDescription += fmt.Sprintf(" (%s)", strings.Join(monitors, ", "))
+			}
+			results = append(results, RuleResult{
+				Name:        "ServiceMonitor",
+				Description: serviceMonitorDescription,
+				Passed:      monitorErr == nil && len(monitors) > 0,
+				Severity:    SeverityWarning,
+			})
+		}
+	}
+
+	// Rule (opt-in): Check an Istio VirtualService routes to the Service, for apps exposed through the mesh,
+	// when the Istio CRDs are installed
+	if opts.ruleEnabled("VirtualService") && service != nil && opts.DynamicClient != nil {
+		gvr, installed, discoveryErr := k8s.VirtualServiceGVR(clientset.Discovery())
+		if discoveryErr != nil && debugLog != nil {
+			debugLog.Printf("VirtualService check skipped: %v", discoveryErr)
+		}
+		if installed {
+			virtualServices, virtualServiceErr := k8s.GetVirtualServicesForService(opts.DynamicClient, gvr, service)
+			virtualServiceDescription := fmt.Sprintf("An Istio VirtualService routes to the Service (%s)", service.Name)
+			switch {
+			case virtualServiceErr != nil:
+				virtualServiceDescription += fmt.Sprintf(" (%v)", virtualServiceErr)
+			case len(virtualServices) == 0:
+				virtualServiceDescription += " (none found, the Service may be unreachable through the gateway)"
+			default:
+				virtualServiceDescription += fmt.Sprintf(" (%s)", strings.Join(virtualServices, ", "))
+			}
+			results = append(results, RuleResult{
+				Name:        "VirtualService",
+				Description: virtualServiceDescription,
+				Passed:      virtualServiceErr == nil && len(virtualServices) > 0,
+				Severity:    SeverityWarning,
+			})
+		}
+	}
+
+	// Rule (opt-in by annotation): Check apps needing sticky sessions keep ClientIP session affinity
+	if requiresStickySessions(service, deployment) {
+		sessionAffinityValid := ValidateSessionAffinity(service)
+		sessionAffinityResult := RuleResult{
+			Name: "Session Affinity",
+			Description: fmt.Sprintf("Service uses sessionAffinity ClientIP as required by %s (current: %s)",
+				stickySessionsAnnotation, sessionAffinityValue(service)),
+			Passed:   sessionAffinityValid,
+			Severity: SeverityCritical,
+		}
+		if !sessionAffinityValid {
+			sessionAffinityResult.FixCommand = sessionAffinityFixCommand(service, namespace)
+		}
+		results = append(results, sessionAffinityResult)
+	}
+
+	// Rule (opt-in): Check CronJobs don't rely on the permissive scheduling defaults
+	if opts.ruleEnabled("CronJob Policies") {
+		cronJobs, cronJobErr := k8s.GetCronJobsByLabel(clientset, namespace, appLabel)
+		var cronJobProblems []string
+		for _, cronJob := range cronJobs {
+			if !ValidateCronJobPolicies(&cronJob) {
+				cronJobProblems = append(cronJobProblems,
+					fmt.Sprintf("%s: %s", cronJob.Name, strings.Join(cronJobPolicyIssues(&cronJob), ", ")))
+			}
+		}
+		cronJobDescription := "CronJobs set concurrencyPolicy (Forbid or Replace) and startingDeadlineSeconds"
+		if cronJobErr == nil && len(cronJobs) == 0 {
+			cronJobDescription += " (no CronJobs)"
+		} else if len(cronJobProblems) > 0 {
+			cronJobDescription += fmt.Sprintf(" (%s)", strings.Join(cronJobProblems, "; "))
+		}
+		results = append(results, RuleResult{
+			Name:        "CronJob Policies",
+			Description: cronJobDescription,
+			Passed:      cronJobErr == nil && len(cronJobProblems) == 0,
+			Severity:    SeverityWarning,
+		})
+	}
+
+	// Rule: Check the app's resources weren't written with deprecated or removed apiVersions
+	apiObjects := make(map[string]metav1.Object)
+	if deployment != nil {
+		apiObjects["Deployment/"+deployment.Name] = deployment
+	}
+	if service != nil {
+		apiObjects["Service/"+service.Name] = service
+	}
+	if hpa != nil {
+		apiObjects["HorizontalPodAutoscaler/"+hpa.Name] = hpa
+	}
+	deprecatedAPIs, apiErr := k8s.FindDeprecatedAPIs(clientset.Discovery(), apiObjects)
+	if apiErr != nil && debugLog != nil {
+		debugLog.Printf("Deprecated API check failed: %v", apiErr)
+	}
+	deprecatedAPIsDescription := "App resources use no deprecated apiVersions for this cluster version"
+	if apiErr != nil {
+		deprecatedAPIsDescription += fmt.Sprintf(" (%v)", apiErr)
+	} else if len(deprecatedAPIs) > 0 {
+		deprecatedAPIsDescription += fmt.Sprintf(" (%s)", strings.Join(deprecatedAPIs, "; "))
+	}
+	results = append(results, RuleResult{
+		Name:        "Deprecated APIs",
+		Description: deprecatedAPIsDescription,
+		Passed:      apiErr == nil && len(deprecatedAPIs) == 0,
+		Severity:    SeverityWarning,
+	})
+
+	// Attach remediation advice to the built-in rules
+	for i := range results {
+		if doc, found := GetRuleDoc(results[i].Name); found {
+			results[i].Remediation = doc.Remediation
+		}
+	}
+
+	// Custom resource rules from the rules config
+	for _, def := range opts.UnstructuredRules {
+		results = append(results, EvaluateUnstructuredRule(opts.DynamicClient, namespace, def))
+	}
+
+	// Custom rules computed by an external program from the fetched resources
+	if opts.RulePlugin != "" {
+		pluginResults, pluginErr := RunRulePlugin(opts.RulePlugin, opts.RulePluginTimeout, RulePluginInput{
+			Namespace:  namespace,
+			Label:      appLabel,
+			Deployment: deployment,
+			Service:    service,
+			Pods:       pods,
+			HPA:        hpa,
+		})
+		if pluginErr != nil {
+			if debugLog != nil {
+				debugLog.Printf("Rule plugin failed: %v", pluginErr)
+			}
+			results = append(results, RuleResult{
+				Name:        "Rule Plugin",
+				Description: fmt.Sprintf("Rule plugin %q ran (%v)", opts.RulePlugin, pluginErr),
+				Passed:      false,
+				Severity:    SeverityWarning,
+			})
+		}
+		results = append(results, pluginResults...)
+	}
+
+	opts.redactResults(results)
+	return results
+}
+
+// RulePluginInput is the JSON document a -rule-plugin command reads on stdin: the app's target and the
+// resources fetched for the built-in rules, null (or an empty list) when not found
+type RulePluginInput struct {
+	Namespace  string                                 `json:"namespace"`
+	Label      string                                 `json:"label"`
+	Deployment *appsv1.Deployment                     `json:"deployment"`
+	Service    *corev1.Service                        `json:"service"`
+	Pods       []corev1.Pod                           `json:"pods"`
+	HPA        *autoscalingv2.HorizontalPodAutoscaler `json:"hpa"`
+}
+
+// maxPluginStderr is how much of a failing plugin's stderr is quoted in the error
+const maxPluginStderr = 200
+
+// RunRulePlugin runs the command (split on spaces, not run through a shell) with the input as JSON on
+// stdin and parses its stdout as a JSON array of rule results. It is killed after the timeout
+// (DefaultRulePluginTimeout when zero), and a nonzero exit is an error quoting its stderr. Results
+// without a name are dropped, and unknown severities become warnings.
+func RunRulePlugin(command string, timeout time.Duration, input RulePluginInput) ([]RuleResult, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	if timeout == 0 {
+		timeout = DefaultRulePluginTimeout
+	}
+	if input.Pods == nil {
+		input.Pods = []corev1.Pod{}
+	}
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding plugin input: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Don't wait on children of the plugin still holding its output open once it is killed
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			message := strings.TrimSpace(stderr.String())
+			if len(message) > maxPluginStderr {
+				message = message[:maxPluginStderr] + "..."
+			}
+			if message != "" {
+				return nil, fmt.Errorf("exited with status %d: %s", exitErr.ExitCode(), message)
+			}
+			return nil, fmt.Errorf("exited with status %d", exitErr.ExitCode())
+		}
+		return nil, err
+	}
+
+	var pluginResults []RuleResult
+	if err := json.Unmarshal(stdout.Bytes(), &pluginResults); err != nil {
+		return nil, fmt.Errorf("invalid output, expected a JSON array of rule results: %v", err)
+	}
+	results := make([]RuleResult, 0, len(pluginResults))
+	for _, result := range pluginResults {
+		if result.Name == "" {
+			continue
+		}
+		result.Severity = strings.ToLower(result.Severity)
+		if _, known := severityRanks[result.Severity]; !known {
+			result.Severity = SeverityWarning
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// serviceLabelSelectors returns the selectors tried in order to find the app's Service:
+// the app label and the Argo CD instance label
+func serviceLabelSelectors(appLabel string) []string {
+	// Clean the label and get the actual value
+	cleanLabel := strings.Trim(strings.TrimPrefix(appLabel, "app="), "\"")
+	return []string{
+		fmt.Sprintf("app=%s", cleanLabel),
+		fmt.Sprintf("argocd.argoproj.io/instance=%s", cleanLabel),
+	}
+}
+
+// RulesCache reuses EvaluateRules results while the app's Deployments, Services and pods keep
+// their resourceVersions, so repeated evaluations (-watch) skip the validators when nothing changed.
+// Rules depending on the time, such as Stuck Rollout, are evaluated again on every read.
+// Other inputs (nodes, HPAs, ConfigMaps...) are not tracked: call Invalidate to force a full
+// re-evaluation now and then. It is safe for concurrent use.
+type RulesCache struct {
+	mu      sync.Mutex
+	key     string
+	results []RuleResult
+}
+
+// NewRulesCache returns an empty cache
+func NewRulesCache() *RulesCache {
+	return &RulesCache{}
+}
+
+// Evaluate returns the cached results when the resourceVersions of the rules' inputs are unchanged
+// since the last evaluation, and evaluates the rules again otherwise
+func (c *RulesCache) Evaluate(clientset kubernetes.Interface, namespace, appLabel string, opts RuleOptions) []RuleResult {
+	key, pods, err := ruleInputsVersion(clientset, namespace, appLabel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Without a version key (e.g. a failed list) there is nothing safe to compare, so always re-evaluate
+	if err == nil && c.results != nil && key == c.key {
+		if debugLog != nil {
+			debugLog.Printf("Rule inputs unchanged (%s), reusing the cached results", key)
+		}
+		results := slices.Clone(c.results)
+		for i := range results {
+			if results[i].Name == "Stuck Rollout" {
+				fresh := stuckRolloutResult(pods, true, time.Now())
+				results[i].Description = opts.Redactor.Redact(fresh.Description)
+				results[i].Passed = fresh.Passed
+			}
+		}
+		return results
+	}
+	results := EvaluateRules(clientset, namespace, appLabel, opts)
+	c.key, c.results = key, nil
+	if err == nil {
+		c.results = slices.Clone(results)
+	}
+	return results
+}
+
+// Invalidate drops the cached results, so the next evaluation runs every rule again
+func (c *RulesCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key, c.results = "", nil
+}
+
+// ruleInputsVersion returns a key built from the resourceVersions of the app's Deployments,
+// Services and pods, which changes whenever one of them is created, updated or deleted, along with the pods
+func ruleInputsVersion(clientset kubernetes.Interface, namespace, appLabel string) (string, []corev1.Pod, error) {
+	var versions []string
+	deployments, err := k8s.GetDeploymentsByLabel(clientset, namespace, appLabel)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, deployment := range deployments {
+		versions = append(versions, "deployment/"+deployment.Name+"@"+deployment.ResourceVersion)
+	}
+	pods, err := k8s.GetPodsByLabel(clientset, namespace, appLabel)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, pod := range pods {
+		versions = append(versions, "pod/"+pod.Name+"@"+pod.ResourceVersion)
+	}
+	for _, selector := range serviceLabelSelectors(appLabel) {
+		services, err := k8s.GetServicesByLabel(clientset, namespace, selector)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, service := range services {
+			versions = append(versions, "service/"+service.Name+"@"+service.ResourceVersion)
+		}
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ","), pods, nil
+}
+
+// redactResults masks cluster-identifying details in the descriptions and fix commands of the results
+func (opts RuleOptions) redactResults(results []RuleResult) {
+	for i := range results {
+		results[i].Description = opts.Redactor.Redact(results[i].Description)
+		results[i].FixCommand = opts.Redactor.Redact(results[i].FixCommand)
+	}
+}
+
+// namespaceConsistencyIssues describes the app's resources left over in a namespace: a Service without a
+// backing Deployment, or a Deployment expecting replicas without any pods. A missing resource on its own, such as
+// no Service for a worker or a Deployment scaled to zero, isn't an issue.
+func namespaceConsistencyIssues(deployment *appsv1.Deployment, service *corev1.Service, pods []corev1.Pod) []string {
+	var issues []string
+	if service != nil && deployment == nil {
+		issues = append(issues, "Service without a backing Deployment, likely left over")
+	}
+	if deployment != nil && specReplicas(deployment) > 0 && len(pods) == 0 {
+		issues = append(issues, "Deployment without pods")
+	}
+	return issues
+}
+
+// ValidateNamespaceConsistency checks the namespace holds no leftover Service or pod-less Deployment of the app
+func ValidateNamespaceConsistency(deployment *appsv1.Deployment, service *corev1.Service, pods []corev1.Pod) bool {
+	return len(namespaceConsistencyIssues(deployment, service, pods)) == 0
+}
+
+// EvaluateNamespaceConsistency evaluates the Namespace Consistency rule for the app named appName in one
+// namespace of an -all-namespaces scan, where the app's resources may be left over in some namespaces
+func EvaluateNamespaceConsistency(clientset kubernetes.Interface, namespace, appName, labelSelector string, opts RuleOptions) RuleResult {
+	var errs []string
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), appName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("error retrieving deployment: %v", err))
+		}
+		deployment = nil
+	}
+	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), appName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("error retrieving service: %v", err))
+		}
+		service = nil
+	}
+	pods, err := k8s.GetPodsByLabel(clientset, namespace, labelSelector)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	problems := append(namespaceConsistencyIssues(deployment, service, pods), errs...)
+	description := "The app's Service has a backing Deployment and the Deployment has pods in the namespace"
+	if len(problems) > 0 {
+		description += fmt.Sprintf(" (%s)", strings.Join(problems, "; "))
+	}
+	result := RuleResult{
+		Name:        "Namespace Consistency",
+		Description: description,
+		Passed:      len(problems) == 0,
+		Severity:    SeverityWarning,
+	}
+	if doc, found := GetRuleDoc(result.Name); found {
+		result.Remediation = doc.Remediation
+	}
+	results := []RuleResult{result}
+	opts.redactResults(results)
+	return results[0]
+}
+
+// Rules panel verbosity levels accepted by FormatRulesCompliance
+const (
+	// RulesVerbositySummary shows only the pass/fail counts
+	RulesVerbositySummary = "summary"
+	// RulesVerbosityFailures shows the counts and the failing rules with their remediation
+	RulesVerbosityFailures = "failures"
+	// RulesVerbosityAll shows every rule
+	RulesVerbosityAll = "all"
+)
+
+// GetRulesCompliance evaluates all rules and returns a formatted compliance report string using the given
+// symbols, at the given verbosity
+func GetRulesCompliance(clientset kubernetes.Interface, namespace string, appLabel string, opts RuleOptions, symbols StatusSymbols,
+	verbosity string) string {
+	// Evaluate all rules
+	results := EvaluateRules(clientset, namespace, appLabel, opts)
+	return FormatRulesCompliance(results, namespace, symbols, verbosity)
+}
+
+// FormatRulesCompliance formats rule results as the compliance report shown in the rules panel.
+// verbosity picks what is listed: RulesVerbosityAll (every rule), RulesVerbosityFailures or
+// RulesVerbositySummary.
+func FormatRulesCompliance(results []RuleResult, namespace string, symbols StatusSymbols, verbosity string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Compliance check for namespace: %s\n\n", namespace))
+
+	if verbosity == RulesVerbositySummary || verbosity == RulesVerbosityFailures {
+		sb.WriteString(rulesSummary(results) + "\n")
+	}
+	if verbosity == RulesVerbositySummary {
+		return sb.String()
+	}
+	if verbosity == RulesVerbosityFailures {
+		sb.WriteString("\n")
+	}
+
+	for _, result := range results {
+		if verbosity == RulesVerbosityFailures && result.Passed {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s %s: %s\n",
+			ResultSymbol(symbols, result),
+			result.Name,
+			result.Description))
+		if verbosity == RulesVerbosityFailures && result.Remediation != "" {
+			sb.WriteString(fmt.Sprintf("    Fix: %s\n", result.Remediation))
+		}
+	}
+
+	return sb.String()
+}
+
+// rulesSummary counts the passing rules and the failing ones per severity, e.g.
+// "Passed: 18/22 rules (2 critical, 2 warning failing)"
+func rulesSummary(results []RuleResult) string {
+	passed := 0
+	failing := make(map[string]int)
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		} else {
+			failing[result.Severity]++
+		}
+	}
+
+	summary := fmt.Sprintf("Passed: %d/%d rules", passed, len(results))
+	var counts []string
+	for _, severity := range []string{SeverityCritical, SeverityWarning, SeverityInfo} {
+		if failing[severity] > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", failing[severity], severity))
+		}
+	}
+	if len(counts) > 0 {
+		summary += fmt.Sprintf(" (%s failing)", strings.Join(counts, ", "))
+	}
+	return summary
 }
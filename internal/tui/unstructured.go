@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	k8s "github.com/kiquetal/k8s-rules-viewer/internal/kubernetes"
+)
+
+// RulesConfig is the YAML rules configuration loaded with -rules-config
+type RulesConfig struct {
+	UnstructuredRules []UnstructuredRuleDefinition `json:"unstructuredRules"`
+}
+
+// UnstructuredRuleDefinition asserts that a field of an arbitrary (custom) resource equals an expected value
+type UnstructuredRuleDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Group       string `json:"group"`
+	Version     string `json:"version"`
+	Kind        string `json:"kind"`
+	// Resource is the plural resource name, guessed from Kind when empty
+	Resource      string `json:"resource"`
+	LabelSelector string `json:"labelSelector"`
+	JSONPath      string `json:"jsonPath"`
+	Expected      string `json:"expected"`
+}
+
+// LoadRulesConfig reads and validates a YAML rules configuration file
+func LoadRulesConfig(path string) (*RulesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules config %s: %v", path, err)
+	}
+
+	var config RulesConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse rules config %s: %v", path, err)
+	}
+
+	for i, def := range config.UnstructuredRules {
+		if def.Name == "" || def.Version == "" || def.Kind == "" || def.JSONPath == "" {
+			return nil, fmt.Errorf("unstructured rule %d in %s needs name, version, kind and jsonPath", i+1, path)
+		}
+	}
+	return &config, nil
+}
+
+// groupVersionResource resolves the resource targeted by the definition
+func (def UnstructuredRuleDefinition) groupVersionResource() schema.GroupVersionResource {
+	if def.Resource != "" {
+		return schema.GroupVersionResource{Group: def.Group, Version: def.Version, Resource: def.Resource}
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(schema.GroupVersionKind{Group: def.Group, Version: def.Version, Kind: def.Kind})
+	return gvr
+}
+
+// UnstructuredListKinds maps the resources targeted by the definitions to their list kind,
+// as needed to serve them from manifests
+func UnstructuredListKinds(defs []UnstructuredRuleDefinition) map[schema.GroupVersionResource]string {
+	kinds := make(map[schema.GroupVersionResource]string)
+	for _, def := range defs {
+		kinds[def.groupVersionResource()] = def.Kind + "List"
+	}
+	return kinds
+}
+
+// EvaluateUnstructuredRule lists the resources targeted by the definition and checks
+// that the JSONPath field of every one of them equals the expected value
+func EvaluateUnstructuredRule(dynClient dynamic.Interface, namespace string, def UnstructuredRuleDefinition) RuleResult {
+	result := RuleResult{
+		Name:        def.Name,
+		Description: def.Description,
+		Severity:    def.Severity,
+	}
+	if result.Description == "" {
+		result.Description = fmt.Sprintf("%s %s is %q", def.Kind, def.JSONPath, def.Expected)
+	}
+	if result.Severity == "" {
+		result.Severity = SeverityWarning
+	}
+	if dynClient == nil {
+		result.Description += " (dynamic client not initialized)"
+		return result
+	}
+
+	// Accept both "{.spec.field}" and ".spec.field"
+	path := def.JSONPath
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	parser := jsonpath.New(def.Name)
+	if err := parser.Parse(path); err != nil {
+		result.Description += fmt.Sprintf(" (invalid jsonPath: %v)", err)
+		return result
+	}
+
+	items, err := k8s.ListUnstructured(dynClient, def.groupVersionResource(), namespace, def.LabelSelector)
+	if err != nil {
+		result.Description += fmt.Sprintf(" (error listing %s: %v)", def.Kind, err)
+		return result
+	}
+	if len(items) == 0 {
+		result.Description += fmt.Sprintf(" (no %s found)", def.Kind)
+		return result
+	}
+
+	var mismatches []string
+	for _, item := range items {
+		values, err := parser.FindResults(item.Object)
+		if err != nil || len(values) == 0 || len(values[0]) == 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s: missing", item.GetName()))
+			continue
+		}
+		actual := fmt.Sprint(values[0][0].Interface())
+		if actual != def.Expected {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %s", item.GetName(), actual))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		result.Description += fmt.Sprintf(" (mismatched: %s)", strings.Join(mismatches, ", "))
+		return result
+	}
+	result.Passed = true
+	return result
+}
@@ -0,0 +1,24 @@
+package tui
+
+import "github.com/rivo/tview"
+
+// unwrappedViews tracks the text views whose line wrapping was turned off, as tview has no
+// getter for the setting. It is only accessed from the UI goroutine.
+var unwrappedViews = make(map[*tview.TextView]bool)
+
+// ToggleWrap turns line wrapping of a text view off, so long lines (stack traces, JSON logs,
+// YAML) scroll horizontally instead, or back on. The view's word wrapping setting is kept.
+func ToggleWrap(view *tview.TextView) {
+	wrap := unwrappedViews[view]
+	if wrap {
+		delete(unwrappedViews, view)
+	} else {
+		unwrappedViews[view] = true
+	}
+	view.SetWrap(wrap)
+}
+
+// forgetWrap drops the wrap state of a view that is no longer shown
+func forgetWrap(view *tview.TextView) {
+	delete(unwrappedViews, view)
+}